@@ -0,0 +1,267 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/config"
+	"github.com/sad0vnikov/radish/helpers"
+	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis/db"
+	"github.com/sad0vnikov/radish/storage"
+)
+
+//Entry is a mutating API call recorded to the audit log
+//OldValue and NewValue describe a key given in the call URL before and after the call,
+//Keys and Details are attached by handlers of calls given keys and commands in their bodies, e.g. batches or console commands
+type Entry struct {
+	Time      int64
+	User      string `json:",omitempty"`
+	ClientIP  string
+	Server    string `json:",omitempty"`
+	DB        *uint8 `json:",omitempty"`
+	Key       string `json:",omitempty"`
+	Operation string
+	//Params are URL and query params of the call except server, db and key
+	Params   map[string]string      `json:",omitempty"`
+	Keys     []string               `json:",omitempty"`
+	Details  map[string]interface{} `json:",omitempty"`
+	OldValue *db.KeyDigest          `json:",omitempty"`
+	NewValue *db.KeyDigest          `json:",omitempty"`
+	Error    string                 `json:",omitempty"`
+}
+
+//Query selects audit log entries, empty fields match any entry
+//KeyPattern is a glob, From and To are an inclusive range of unix timestamps
+type Query struct {
+	Server     string
+	KeyPattern string
+	User       string
+	From       int64
+	To         int64
+	Limit      int
+}
+
+const logFileName = "audit.log"
+
+var (
+	auditLog     *storage.JSONLinesFile
+	maxValueSize int
+)
+
+//Init opens the audit log in Radish data directory
+func Init(c config.Config) {
+	if auditLog != nil {
+		auditLog.Close()
+	}
+	auditLog = storage.NewJSONLinesFile(filepath.Join(c.DataDir, logFileName), int64(c.Audit.MaxFileSize)*1024*1024, c.Audit.MaxFiles)
+	maxValueSize = c.Audit.MaxValueSize
+}
+
+//Recording is an API call being recorded, the entry is written to the log when the call is finished
+type Recording struct {
+	entry   Entry
+	skipped bool
+}
+
+//secretParams are query params which are not recorded, e.g. confirmation tokens
+var secretParams = map[string]bool{"token": true}
+
+type contextKey int
+
+const recordingContextKey contextKey = 0
+
+//Start begins recording a mutating API call, the value of a key given in 'key' URL param is captured before the call
+//nil is returned if the audit log is not initialized
+func Start(r *http.Request, operation string) *Recording {
+	if auditLog == nil {
+		return nil
+	}
+
+	entry := Entry{Time: time.Now().Unix(), ClientIP: clientIP(r), Operation: operation, Params: make(map[string]string)}
+	if user, ok := auth.RequestUser(r); ok {
+		entry.User = user.ID()
+	}
+
+	for name, value := range mux.Vars(r) {
+		switch name {
+		case "server":
+			entry.Server = value
+		case "key":
+			entry.Key = value
+		default:
+			entry.Params[name] = value
+		}
+	}
+	for name, values := range r.URL.Query() {
+		if secretParams[name] {
+			continue
+		}
+		if name == "db" {
+			if dbNum, err := strconv.ParseUint(values[0], 10, 8); err == nil {
+				n := uint8(dbNum)
+				entry.DB = &n
+			}
+			continue
+		}
+		entry.Params[name] = values[0]
+	}
+	if len(entry.Key) > 0 && entry.DB == nil {
+		n := uint8(0)
+		entry.DB = &n
+	}
+
+	rec := &Recording{entry: entry}
+	rec.entry.OldValue = rec.keyDigest()
+	return rec
+}
+
+//WithRequest returns a copy of the request carrying the recording, so handlers can attach keys and details to it
+func (rec *Recording) WithRequest(r *http.Request) *http.Request {
+	if rec == nil {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), recordingContextKey, rec))
+}
+
+//AddKeys attaches keys changed by a call to its recording, it does nothing if the request is not recorded
+func AddKeys(r *http.Request, keys ...string) {
+	if rec, ok := r.Context().Value(recordingContextKey).(*Recording); ok {
+		rec.entry.Keys = append(rec.entry.Keys, keys...)
+	}
+}
+
+//Skip drops the recording of a call which hasn't changed anything, e.g. a call returning a confirmation token
+func Skip(r *http.Request) {
+	if rec, ok := r.Context().Value(recordingContextKey).(*Recording); ok {
+		rec.skipped = true
+	}
+}
+
+//AddDetail attaches a named detail of a call to its recording, e.g. a console command or an old config value,
+//strings longer than the audit MaxValueSize are replaced with their sizes
+func AddDetail(r *http.Request, name string, value interface{}) {
+	rec, ok := r.Context().Value(recordingContextKey).(*Recording)
+	if !ok {
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		value = truncate(v)
+	case []string:
+		truncated := make([]string, len(v))
+		for i, s := range v {
+			truncated[i] = truncate(s)
+		}
+		value = truncated
+	}
+	if rec.entry.Details == nil {
+		rec.entry.Details = make(map[string]interface{})
+	}
+	rec.entry.Details[name] = value
+}
+
+func truncate(s string) string {
+	if len(s) > maxValueSize {
+		return "<" + strconv.Itoa(len(s)) + " bytes>"
+	}
+	return s
+}
+
+//Finish captures the key value after the call and writes the entry to the log
+func (rec *Recording) Finish(err error) {
+	if rec == nil || rec.skipped {
+		return
+	}
+
+	if err != nil {
+		rec.entry.Error = err.Error()
+	}
+	rec.entry.NewValue = rec.keyDigest()
+
+	appendErr := auditLog.Append(rec.entry)
+	if appendErr != nil {
+		logger.Info("can't write to audit log: " + appendErr.Error())
+	}
+}
+
+func (rec *Recording) keyDigest() *db.KeyDigest {
+	if len(rec.entry.Server) == 0 || len(rec.entry.Key) == 0 {
+		return nil
+	}
+
+	digest, err := db.GetKeyDigest(rec.entry.Server, *rec.entry.DB, rec.entry.Key, maxValueSize)
+	if err != nil {
+		logger.Info("can't get key digest for audit log: " + err.Error())
+		return nil
+	}
+	return &digest
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+//Search returns the latest entries matching the query, entries are filtered with visible func before the limit is applied
+func Search(q Query, visible func(Entry) bool) ([]Entry, error) {
+	result := []Entry{}
+	if auditLog == nil {
+		return result, nil
+	}
+
+	keyPattern := helpers.GlobToRegexp(q.KeyPattern)
+	err := auditLog.ReadReverse(func(line []byte) (bool, error) {
+		var entry Entry
+		err := json.Unmarshal(line, &entry)
+		if err != nil {
+			return false, err
+		}
+
+		if (q.From > 0 && entry.Time < q.From) || (q.To > 0 && entry.Time > q.To) {
+			return true, nil
+		}
+		if len(q.Server) > 0 && entry.Server != q.Server {
+			return true, nil
+		}
+		if len(q.User) > 0 && entry.User != q.User {
+			return true, nil
+		}
+		if len(q.KeyPattern) > 0 && !matchesKey(entry, keyPattern) {
+			return true, nil
+		}
+		if !visible(entry) {
+			return true, nil
+		}
+
+		result = append(result, entry)
+		return q.Limit <= 0 || len(result) < q.Limit, nil
+	})
+
+	return result, err
+}
+
+//matchesKey returns true if a key given in the call URL or any of the keys attached by its handler matches the pattern
+func matchesKey(entry Entry, keyPattern *regexp.Regexp) bool {
+	if keyPattern.MatchString(entry.Key) {
+		return true
+	}
+	for _, key := range entry.Keys {
+		if keyPattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sad0vnikov/radish/config"
+)
+
+func TestRecordingAndSearchingEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radish-test-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config.StubConfigLoader{DataDir: dir}.Load()
+	Init(config.Get())
+	defer func() {
+		auditLog.Close()
+		auditLog = nil
+	}()
+
+	r := httptest.NewRequest("POST", "/api/v1/servers/server1/flushdb?db=3&async=true", nil)
+	r = mux.SetURLVars(r, map[string]string{"server": "server1"})
+	Start(r, "POST v1/servers/{server}/flushdb").Finish(nil)
+
+	r = httptest.NewRequest("POST", "/api/v1/servers/server2/flushall", nil)
+	r = mux.SetURLVars(r, map[string]string{"server": "server2"})
+	Start(r, "POST v1/servers/{server}/flushall").Finish(errors.New("server server2 is in protected mode"))
+
+	all := func(Entry) bool { return true }
+	entries, err := Search(Query{}, all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Server != "server2" || entries[0].Error == "" {
+		t.Fatalf("got entries %+v, expected the latest failed flushall first", entries)
+	}
+	first := entries[1]
+	if first.DB == nil || *first.DB != 3 || first.Params["async"] != "true" || first.ClientIP != "192.0.2.1" {
+		t.Errorf("got invalid entry %+v", first)
+	}
+
+	entries, _ = Search(Query{Server: "server1"}, all)
+	if len(entries) != 1 || entries[0].Server != "server1" {
+		t.Errorf("got entries %+v for server1", entries)
+	}
+	entries, _ = Search(Query{To: first.Time - 1}, all)
+	if len(entries) != 0 {
+		t.Errorf("got entries %+v older than all recorded ones", entries)
+	}
+	entries, _ = Search(Query{}, func(e Entry) bool { return e.Server != "server2" })
+	if len(entries) != 1 || entries[0].Server != "server1" {
+		t.Errorf("got entries %+v, expected server2 entries to be hidden", entries)
+	}
+}
+
+func TestAttachingKeysAndDetails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radish-test-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config.StubConfigLoader{DataDir: dir}.Load()
+	Init(config.Get())
+	defer func() {
+		auditLog.Close()
+		auditLog = nil
+	}()
+
+	r := httptest.NewRequest("POST", "/api/v1/servers/server1/batch", nil)
+	r = mux.SetURLVars(r, map[string]string{"server": "server1"})
+	AddKeys(r, "ignored")
+	rec := Start(r, "POST v1/servers/{server}/batch")
+	r = rec.WithRequest(r)
+	AddKeys(r, "user:1", "user:2")
+	AddDetail(r, "Operations", []string{"set", strings.Repeat("x", maxValueSize+1)})
+	rec.Finish(nil)
+
+	entries, err := Search(Query{KeyPattern: "user:2"}, func(Entry) bool { return true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !reflect.DeepEqual(entries[0].Keys, []string{"user:1", "user:2"}) {
+		t.Fatalf("got entries %+v, expected the batch entry with its keys", entries)
+	}
+	expected := []interface{}{"set", "<" + strconv.Itoa(maxValueSize+1) + " bytes>"}
+	if !reflect.DeepEqual(entries[0].Details["Operations"], expected) {
+		t.Errorf("got details %+v, expected %v", entries[0].Details, expected)
+	}
+}
+
+func TestSkippingConfirmationTokenCalls(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radish-test-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config.StubConfigLoader{DataDir: dir}.Load()
+	Init(config.Get())
+	defer func() {
+		auditLog.Close()
+		auditLog = nil
+	}()
+
+	r := httptest.NewRequest("POST", "/api/v1/servers/server1/flushall", nil)
+	r = mux.SetURLVars(r, map[string]string{"server": "server1"})
+	rec := Start(r, "POST v1/servers/{server}/flushall")
+	Skip(rec.WithRequest(r))
+	rec.Finish(nil)
+
+	r = httptest.NewRequest("POST", "/api/v1/servers/server1/flushall?token=secret", nil)
+	r = mux.SetURLVars(r, map[string]string{"server": "server1"})
+	Start(r, "POST v1/servers/{server}/flushall").Finish(nil)
+
+	entries, err := Search(Query{}, func(Entry) bool { return true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].Params) != 0 {
+		t.Errorf("got entries %+v, expected only the confirmed call without its token", entries)
+	}
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sad0vnikov/radish/config"
+)
+
+//User is an authenticated Radish user
+type User struct {
+	Name string
+	//Provider is a name of the way the user was authenticated with, e.g. 'local', 'token' or 'oidc'
+	Provider string
+	Groups   []string `json:",omitempty"`
+}
+
+//ID returns a user identity unique among all providers, it's the name for local users and the name prefixed
+//with the provider name for others, e.g. 'oidc:alice' or 'token:ci'
+func (u User) ID() string {
+	if len(u.Provider) == 0 || u.Provider == "local" {
+		return u.Name
+	}
+	return u.Provider + ":" + u.Name
+}
+
+//PasswordProvider authenticates users with a name and a password
+type PasswordProvider interface {
+	Authenticate(name, password string) (User, error)
+}
+
+var (
+	//ErrInvalidCredentials is returned when a user name or a password is wrong
+	ErrInvalidCredentials = errors.New("invalid user name or password")
+	//ErrUnauthenticated is returned when a request has no valid session or API token
+	ErrUnauthenticated = errors.New("authentication required")
+	//ErrCSRFTokenMismatch is returned when a session request changing data has no valid CSRF token
+	ErrCSRFTokenMismatch = errors.New("CSRF token is missing or invalid")
+)
+
+const (
+	//SessionCookieName is a name of the cookie storing session ID
+	SessionCookieName = "radish_session"
+	//CSRFHeaderName is a header session requests changing data should pass CSRF token in
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+type contextKey int
+
+const (
+	userContextKey contextKey = iota
+	sessionContextKey
+)
+
+var (
+	enabled           bool
+	passwordProviders []PasswordProvider
+	//apiTokens maps SHA-256 digests of API tokens to their names
+	apiTokens    = make(map[string]string)
+	sessions     = newSessionStore(12 * time.Hour)
+	oidcProvider *OIDCProvider
+)
+
+//Init configures authentication, it's enabled if any users, API tokens or OIDC are configured
+func Init(c config.AuthConfig) error {
+	passwordProviders = nil
+	apiTokens = make(map[string]string)
+	sessions = newSessionStore(time.Duration(c.SessionTTL) * time.Minute)
+	oidcProvider = nil
+	enabled = false
+
+	err := initRoles(c)
+	if err != nil {
+		return err
+	}
+
+	if len(c.Users) > 0 {
+		AddPasswordProvider(NewLocalProvider(c.Users))
+	}
+	for _, token := range c.APITokens {
+		apiTokens[strings.ToLower(token.TokenSHA256)] = token.Name
+		enabled = true
+	}
+
+	if c.OIDC != nil {
+		p, err := NewOIDCProvider(context.Background(), *c.OIDC)
+		if err != nil {
+			return err
+		}
+		oidcProvider = p
+		enabled = true
+	}
+
+	return nil
+}
+
+//OIDC returns OpenID Connect provider if it's configured
+func OIDC() (*OIDCProvider, bool) {
+	return oidcProvider, oidcProvider != nil
+}
+
+//AddPasswordProvider adds a way to authenticate users with a password and turns authentication on
+func AddPasswordProvider(p PasswordProvider) {
+	passwordProviders = append(passwordProviders, p)
+	enabled = true
+}
+
+//Enabled returns true if Radish requires authentication
+func Enabled() bool {
+	return enabled
+}
+
+//Login checks user credentials with password providers and starts a new session
+func Login(name, password string) (Session, error) {
+	for _, p := range passwordProviders {
+		user, err := p.Authenticate(name, password)
+		if err == ErrInvalidCredentials {
+			continue
+		}
+		if err != nil {
+			return Session{}, err
+		}
+		return CreateSession(user)
+	}
+
+	return Session{}, ErrInvalidCredentials
+}
+
+//CreateSession starts a new session for an authenticated user
+func CreateSession(user User) (Session, error) {
+	return sessions.create(user)
+}
+
+//Logout ends a session
+func Logout(sessionID string) {
+	sessions.delete(sessionID)
+}
+
+//Authenticate checks request API token or session and returns the request with the user stored in its context
+//session requests with methods other than GET and HEAD should pass session CSRF token in X-CSRF-Token header
+func Authenticate(r *http.Request) (*http.Request, error) {
+	if !enabled {
+		return r, nil
+	}
+
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		name, ok := findAPIToken(strings.TrimPrefix(header, "Bearer "))
+		if !ok {
+			return r, ErrUnauthenticated
+		}
+		return withUser(r, User{Name: name, Provider: "token"}), nil
+	}
+
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return r, ErrUnauthenticated
+	}
+	session, ok := sessions.get(cookie.Value)
+	if !ok {
+		return r, ErrUnauthenticated
+	}
+
+	if r.Method != "GET" && r.Method != "HEAD" {
+		csrfToken := r.Header.Get(CSRFHeaderName)
+		if subtle.ConstantTimeCompare([]byte(csrfToken), []byte(session.CSRFToken)) != 1 {
+			return r, ErrCSRFTokenMismatch
+		}
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), sessionContextKey, session))
+	return withUser(r, session.User), nil
+}
+
+func findAPIToken(token string) (string, bool) {
+	sum := sha256.Sum256([]byte(token))
+	digest := hex.EncodeToString(sum[:])
+	for tokenDigest, name := range apiTokens {
+		if subtle.ConstantTimeCompare([]byte(digest), []byte(tokenDigest)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func withUser(r *http.Request, user User) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), userContextKey, user))
+}
+
+//RequestUser returns a user authenticated the request
+func RequestUser(r *http.Request) (User, bool) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	return user, ok
+}
+
+//RequestSession returns a session the request was authenticated with
+func RequestSession(r *http.Request) (Session, bool) {
+	session, ok := r.Context().Value(sessionContextKey).(Session)
+	return session, ok
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sad0vnikov/radish/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func initTestAuth(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Init(config.AuthConfig{
+		Users: []config.LocalUser{{Name: "admin", PasswordHash: string(hash)}},
+		//SHA-256 of 'ci-token'
+		APITokens:  []config.APIToken{{Name: "ci", TokenSHA256: "948b8c2427cd29047839b8e4a27a08763f8befbafa86be5cce8e46217d75e58a"}},
+		SessionTTL: 60,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoggingIn(t *testing.T) {
+	initTestAuth(t)
+
+	if _, err := Login("admin", "wrong"); err != ErrInvalidCredentials {
+		t.Errorf("got error %v, expected ErrInvalidCredentials", err)
+	}
+	if _, err := Login("nobody", "secret"); err != ErrInvalidCredentials {
+		t.Errorf("got error %v, expected ErrInvalidCredentials", err)
+	}
+
+	session, err := Login("admin", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("GET", "/api/v1/servers", nil)
+	r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: session.ID})
+	r, err = Authenticate(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := RequestUser(r); user.Name != "admin" {
+		t.Errorf("got user %+v, expected admin", user)
+	}
+
+	r = httptest.NewRequest("DELETE", "/api/v1/servers/server1/keys/key", nil)
+	r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: session.ID})
+	if _, err := Authenticate(r); err != ErrCSRFTokenMismatch {
+		t.Errorf("got error %v, expected ErrCSRFTokenMismatch", err)
+	}
+
+	r.Header.Set(CSRFHeaderName, session.CSRFToken)
+	if _, err := Authenticate(r); err != nil {
+		t.Errorf("got error %v for a request with CSRF token", err)
+	}
+
+	Logout(session.ID)
+	if _, err := Authenticate(r); err != ErrUnauthenticated {
+		t.Errorf("got error %v after logout, expected ErrUnauthenticated", err)
+	}
+}
+
+func TestAuthenticatingWithAPIToken(t *testing.T) {
+	initTestAuth(t)
+
+	r := httptest.NewRequest("POST", "/api/v1/servers/server1/console", nil)
+	r.Header.Set("Authorization", "Bearer ci-token")
+	r, err := Authenticate(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := RequestUser(r); user.Name != "ci" || user.Provider != "token" {
+		t.Errorf("got user %+v, expected ci token user", user)
+	}
+
+	r = httptest.NewRequest("GET", "/api/v1/servers", nil)
+	r.Header.Set("Authorization", "Bearer wrong")
+	if _, err := Authenticate(r); err != ErrUnauthenticated {
+		t.Errorf("got error %v, expected ErrUnauthenticated", err)
+	}
+}
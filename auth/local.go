@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"github.com/sad0vnikov/radish/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+//LocalProvider authenticates users configured in Radish config
+type LocalProvider struct {
+	passwordHashes map[string][]byte
+}
+
+//dummyPasswordHash is compared for unknown users, so they can't be told from existing ones by response time
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("radish"), bcrypt.DefaultCost)
+
+//NewLocalProvider returns a provider for users with bcrypt-hashed passwords
+func NewLocalProvider(users []config.LocalUser) *LocalProvider {
+	p := &LocalProvider{passwordHashes: make(map[string][]byte)}
+	for _, user := range users {
+		p.passwordHashes[user.Name] = []byte(user.PasswordHash)
+	}
+	return p
+}
+
+//Authenticate checks a user password
+func (p *LocalProvider) Authenticate(name, password string) (User, error) {
+	hash, ok := p.passwordHashes[name]
+	if !ok {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return User{}, ErrInvalidCredentials
+	}
+
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return User{}, ErrInvalidCredentials
+	}
+
+	return User{Name: name, Provider: "local"}, nil
+}
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/sad0vnikov/radish/config"
+	"golang.org/x/oauth2"
+)
+
+const (
+	//defaultUsernameClaim is the subject identifier, unlike names and emails it's never reassigned to another user
+	defaultUsernameClaim = "sub"
+	defaultGroupsClaim   = "groups"
+	//oidcLoginTTL is a time a user has to log in at the identity provider
+	oidcLoginTTL = 10 * time.Minute
+	//maxPendingOIDCLogins limits logins started but not finished yet, the oldest login is dropped when it's exceeded
+	maxPendingOIDCLogins = 1000
+	//OIDCStateCookieName is a name of the cookie binding a started OIDC login to the browser
+	OIDCStateCookieName = "radish_oidc_state"
+)
+
+var (
+	//ErrOIDCLoginNotFound is returned when OIDC callback state doesn't match any started login
+	ErrOIDCLoginNotFound = errors.New("OIDC login is not started or has expired")
+	//ErrOIDCStateMismatch is returned when OIDC callback state doesn't match the state cookie of the browser
+	ErrOIDCStateMismatch = errors.New("OIDC login was started in another browser")
+)
+
+//GroupNotAllowedError is returned when none of user groups is allowed to log in
+type GroupNotAllowedError struct {
+	User string
+}
+
+func (err GroupNotAllowedError) Error() string {
+	return "user " + err.User + " is not a member of any group allowed to use Radish"
+}
+
+type pendingOIDCLogin struct {
+	codeVerifier string
+	nonce        string
+	expires      time.Time
+}
+
+//OIDCProvider authenticates users with OpenID Connect authorization code flow with PKCE
+type OIDCProvider struct {
+	oauth2Config  oauth2.Config
+	verifier      *oidc.IDTokenVerifier
+	usernameClaim string
+	groupsClaim   string
+	groupMapping  map[string]string
+	allowedGroups []string
+
+	mu      sync.Mutex
+	pending map[string]pendingOIDCLogin
+}
+
+//NewOIDCProvider loads the identity provider discovery document and returns a provider
+func NewOIDCProvider(ctx context.Context, c config.OIDCConfig) (*OIDCProvider, error) {
+	provider, err := oidc.NewProvider(ctx, c.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &OIDCProvider{
+		oauth2Config: oauth2.Config{
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			RedirectURL:  c.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       append([]string{oidc.ScopeOpenID}, c.Scopes...),
+		},
+		verifier:      provider.Verifier(&oidc.Config{ClientID: c.ClientID}),
+		usernameClaim: c.UsernameClaim,
+		groupsClaim:   c.GroupsClaim,
+		groupMapping:  c.GroupMapping,
+		allowedGroups: c.AllowedGroups,
+		pending:       make(map[string]pendingOIDCLogin),
+	}
+	if len(p.usernameClaim) == 0 {
+		p.usernameClaim = defaultUsernameClaim
+	}
+	if len(p.groupsClaim) == 0 {
+		p.groupsClaim = defaultGroupsClaim
+	}
+
+	return p, nil
+}
+
+//AuthCodeURL starts a login and returns the identity provider URL the user should be redirected to
+//and the login state which should be set to the browser with SetOIDCStateCookie
+func (p *OIDCProvider) AuthCodeURL() (string, string, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier := oauth2.GenerateVerifier()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	oldest := ""
+	for s, login := range p.pending {
+		if now.After(login.expires) {
+			delete(p.pending, s)
+			continue
+		}
+		if len(oldest) == 0 || login.expires.Before(p.pending[oldest].expires) {
+			oldest = s
+		}
+	}
+	if len(p.pending) >= maxPendingOIDCLogins {
+		delete(p.pending, oldest)
+	}
+	p.pending[state] = pendingOIDCLogin{codeVerifier: codeVerifier, nonce: nonce, expires: now.Add(oidcLoginTTL)}
+
+	return p.oauth2Config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier)), state, nil
+}
+
+//Exchange finishes a login started with AuthCodeURL exchanging the authorization code for a verified ID token
+//browserState is a value of the state cookie sent by the browser, it should match the callback state
+func (p *OIDCProvider) Exchange(ctx context.Context, state, browserState, code string) (User, error) {
+	if subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return User{}, ErrOIDCStateMismatch
+	}
+
+	p.mu.Lock()
+	login, ok := p.pending[state]
+	delete(p.pending, state)
+	p.mu.Unlock()
+	if !ok || time.Now().After(login.expires) {
+		return User{}, ErrOIDCLoginNotFound
+	}
+
+	token, err := p.oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(login.codeVerifier))
+	if err != nil {
+		return User{}, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return User{}, errors.New("token response has no ID token")
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return User{}, err
+	}
+	if idToken.Nonce != login.nonce {
+		return User{}, errors.New("ID token nonce doesn't match")
+	}
+
+	var claims map[string]interface{}
+	err = idToken.Claims(&claims)
+	if err != nil {
+		return User{}, err
+	}
+
+	name, _ := claims[p.usernameClaim].(string)
+	if len(name) == 0 {
+		return User{}, fmt.Errorf("ID token has no %v claim", p.usernameClaim)
+	}
+
+	user := User{Name: name, Provider: "oidc", Groups: p.mapGroups(claimStrings(claims[p.groupsClaim]))}
+	if !p.groupAllowed(user.Groups) {
+		return User{}, GroupNotAllowedError{User: name}
+	}
+
+	return user, nil
+}
+
+//SetOIDCStateCookie sends a cookie binding a started OIDC login to the browser
+func SetOIDCStateCookie(w http.ResponseWriter, r *http.Request, state string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OIDCStateCookieName,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   int(oidcLoginTTL / time.Second),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+//ClearOIDCStateCookie removes OIDC login state cookie
+func ClearOIDCStateCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OIDCStateCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (p *OIDCProvider) mapGroups(groups []string) []string {
+	if len(p.groupMapping) == 0 {
+		return groups
+	}
+
+	mapped := []string{}
+	for _, group := range groups {
+		if radishGroup, ok := p.groupMapping[group]; ok {
+			mapped = append(mapped, radishGroup)
+		}
+	}
+	return mapped
+}
+
+func (p *OIDCProvider) groupAllowed(groups []string) bool {
+	if len(p.allowedGroups) == 0 {
+		return true
+	}
+
+	for _, allowed := range p.allowedGroups {
+		for _, group := range groups {
+			if group == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//claimStrings converts a claim which can be a single string or a list of strings
+func claimStrings(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return []string{}
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sad0vnikov/radish/config"
+)
+
+//testIdentityProvider is a minimal OpenID Connect provider issuing ID tokens for a single authorization code
+type testIdentityProvider struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	claims        map[string]interface{}
+	codeChallenge string
+	nonce         string
+}
+
+func newTestIdentityProvider(t *testing.T, claims map[string]interface{}) *testIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &testIdentityProvider{key: key, claims: claims}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/authorize",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"jwks_uri":                              idp.server.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": "test",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "test-code" || base64.RawURLEncoding.EncodeToString(verifierHash[:]) != idp.codeChallenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idp.idToken(t),
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+
+	return idp
+}
+
+//authorize plays the user logging in at the identity provider and returns the callback state
+func (idp *testIdentityProvider) authorize(t *testing.T, authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		t.Errorf("got code challenge method %v, expected S256", query.Get("code_challenge_method"))
+	}
+	idp.codeChallenge = query.Get("code_challenge")
+	idp.nonce = query.Get("nonce")
+	return query.Get("state")
+}
+
+func (idp *testIdentityProvider) idToken(t *testing.T) string {
+	claims := map[string]interface{}{
+		"iss":   idp.server.URL,
+		"sub":   "1",
+		"aud":   "radish",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": idp.nonce,
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "test"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestOIDCProvider(t *testing.T, idp *testIdentityProvider, allowedGroups []string) *OIDCProvider {
+	provider, err := NewOIDCProvider(context.Background(), config.OIDCConfig{
+		Issuer:        idp.server.URL,
+		ClientID:      "radish",
+		ClientSecret:  "secret",
+		RedirectURL:   "http://radish.local/api/v1/auth/oidc/callback",
+		GroupMapping:  map[string]string{"redis-admins": "admins", "developers": "developers"},
+		AllowedGroups: allowedGroups,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestLoggingInWithOIDC(t *testing.T) {
+	idp := newTestIdentityProvider(t, map[string]interface{}{
+		"sub":                "f47ac10b",
+		"preferred_username": "alice",
+		"groups":             []string{"redis-admins", "accounting"},
+	})
+	defer idp.server.Close()
+	provider := newTestOIDCProvider(t, idp, []string{"admins"})
+
+	authURL, browserState, err := provider.AuthCodeURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := idp.authorize(t, authURL)
+	if state != browserState {
+		t.Errorf("got state %v in the login URL, expected the browser state %v", state, browserState)
+	}
+
+	if _, err := provider.Exchange(context.Background(), "unknown", "unknown", "test-code"); err != ErrOIDCLoginNotFound {
+		t.Errorf("got error %v for unknown state, expected ErrOIDCLoginNotFound", err)
+	}
+	if _, err := provider.Exchange(context.Background(), state, "", "test-code"); err != ErrOIDCStateMismatch {
+		t.Errorf("got error %v for a callback without the state cookie, expected ErrOIDCStateMismatch", err)
+	}
+
+	user, err := provider.Exchange(context.Background(), state, browserState, "test-code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := User{Name: "f47ac10b", Provider: "oidc", Groups: []string{"admins"}}
+	if !reflect.DeepEqual(user, expected) {
+		t.Errorf("got user %+v, expected %+v", user, expected)
+	}
+
+	if _, err := provider.Exchange(context.Background(), state, browserState, "test-code"); err != ErrOIDCLoginNotFound {
+		t.Errorf("got error %v for a reused state, expected ErrOIDCLoginNotFound", err)
+	}
+}
+
+func TestRefusingOIDCUserWithoutAllowedGroup(t *testing.T) {
+	idp := newTestIdentityProvider(t, map[string]interface{}{
+		"preferred_username": "bob",
+		"groups":             "developers",
+	})
+	defer idp.server.Close()
+	provider := newTestOIDCProvider(t, idp, []string{"admins"})
+
+	authURL, state, err := provider.AuthCodeURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp.authorize(t, authURL)
+
+	_, err = provider.Exchange(context.Background(), state, state, "test-code")
+	if _, ok := err.(GroupNotAllowedError); !ok {
+		t.Errorf("got error %v, expected GroupNotAllowedError", err)
+	}
+}
+
+func TestLimitingPendingOIDCLogins(t *testing.T) {
+	idp := newTestIdentityProvider(t, nil)
+	defer idp.server.Close()
+	provider := newTestOIDCProvider(t, idp, nil)
+
+	_, first, err := provider.AuthCodeURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	for i := 0; i < maxPendingOIDCLogins; i++ {
+		provider.AuthCodeURL()
+	}
+
+	if len(provider.pending) != maxPendingOIDCLogins {
+		t.Errorf("got %v pending logins, expected %v", len(provider.pending), maxPendingOIDCLogins)
+	}
+	if _, ok := provider.pending[first]; ok {
+		t.Errorf("expected the oldest login to be dropped")
+	}
+}
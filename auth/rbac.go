@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sad0vnikov/radish/config"
+	"github.com/sad0vnikov/radish/helpers"
+	"github.com/sad0vnikov/radish/redis"
+)
+
+const (
+	//AllServers is a Permission Server value meaning every server, it's granted by rules not restricted to some servers
+	AllServers = ""
+	//AllDatabases is a Permission DB value meaning every database of a server
+	AllDatabases = -1
+	//AllKeys is a Permission Key value meaning every key of a database
+	AllKeys = ""
+)
+
+//Permission is an access to Radish data a request needs
+type Permission struct {
+	Server string
+	//DB is a database number or AllDatabases
+	DB int
+	//Key is a key name or AllKeys
+	Key   string
+	Level redis.AccessLevel
+}
+
+//AccessDeniedError is returned when user roles don't grant a permission
+type AccessDeniedError struct {
+	User       string
+	Permission Permission
+}
+
+func (err AccessDeniedError) Error() string {
+	msg := fmt.Sprintf("user %v is not allowed to %v on server %v", err.User, verbNames[err.Permission.Level], err.Permission.Server)
+	if err.Permission.Server == AllServers {
+		msg = fmt.Sprintf("user %v is not allowed to %v on all servers", err.User, verbNames[err.Permission.Level])
+	}
+	if err.Permission.DB != AllDatabases {
+		msg += fmt.Sprintf(" in db %v", err.Permission.DB)
+	}
+	if err.Permission.Key != AllKeys {
+		msg += fmt.Sprintf(" for key %v", err.Permission.Key)
+	}
+	return msg
+}
+
+var verbNames = map[redis.AccessLevel]string{
+	redis.AccessRead:  "read",
+	redis.AccessWrite: "write",
+	redis.AccessAdmin: "admin",
+}
+
+//rule is a parsed config.PermissionRule
+type rule struct {
+	servers []*regexp.Regexp
+	//dbFrom and dbTo are an inclusive databases range, dbFrom is AllDatabases if the rule is not restricted
+	dbFrom int
+	dbTo   int
+	keys   *regexp.Regexp
+	levels map[redis.AccessLevel]bool
+}
+
+var (
+	//roles maps role names to their rules, role-based access control is off if it's empty
+	roles      = make(map[string][]rule)
+	userRoles  = make(map[string][]string)
+	groupRoles = make(map[string][]string)
+)
+
+//initRoles parses roles and role bindings from config
+func initRoles(c config.AuthConfig) error {
+	roles = make(map[string][]rule)
+	userRoles = make(map[string][]string)
+	groupRoles = make(map[string][]string)
+
+	for _, role := range c.Roles {
+		rules := make([]rule, 0, len(role.Rules))
+		for _, r := range role.Rules {
+			parsed, err := parseRule(r)
+			if err != nil {
+				return fmt.Errorf("role %v: %v", role.Name, err)
+			}
+			rules = append(rules, parsed)
+		}
+		roles[role.Name] = rules
+	}
+
+	for _, binding := range c.RoleBindings {
+		if _, ok := roles[binding.Role]; !ok {
+			return fmt.Errorf("role binding refers to unknown role %v", binding.Role)
+		}
+		for _, user := range binding.Users {
+			userRoles[user] = append(userRoles[user], binding.Role)
+		}
+		for _, group := range binding.Groups {
+			groupRoles[group] = append(groupRoles[group], binding.Role)
+		}
+	}
+
+	return nil
+}
+
+func parseRule(r config.PermissionRule) (rule, error) {
+	parsed := rule{dbFrom: AllDatabases, dbTo: AllDatabases, levels: make(map[redis.AccessLevel]bool)}
+
+	for _, server := range r.Servers {
+		parsed.servers = append(parsed.servers, helpers.GlobToRegexp(server))
+	}
+	if len(r.Keys) > 0 && r.Keys != "*" {
+		parsed.keys = helpers.GlobToRegexp(r.Keys)
+	}
+
+	if len(r.Databases) > 0 {
+		bounds := strings.SplitN(r.Databases, "-", 2)
+		from, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 8)
+		if err != nil {
+			return rule{}, fmt.Errorf("invalid databases range %v", r.Databases)
+		}
+		to := from
+		if len(bounds) == 2 {
+			to, err = strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 8)
+			if err != nil || to < from {
+				return rule{}, fmt.Errorf("invalid databases range %v", r.Databases)
+			}
+		}
+		parsed.dbFrom, parsed.dbTo = int(from), int(to)
+	}
+
+	if len(r.Verbs) == 0 {
+		return rule{}, fmt.Errorf("rule has no verbs")
+	}
+	for _, verb := range r.Verbs {
+		level, ok := verbLevel(verb)
+		if !ok {
+			return rule{}, fmt.Errorf("unknown verb %v, expected read, write or admin", verb)
+		}
+		parsed.levels[level] = true
+	}
+
+	return parsed, nil
+}
+
+func verbLevel(verb string) (redis.AccessLevel, bool) {
+	for level, name := range verbNames {
+		if strings.EqualFold(verb, name) {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+//matchesServer returns true if the rule is about a server
+func (r rule) matchesServer(server string) bool {
+	if len(r.servers) == 0 {
+		return true
+	}
+	for _, s := range r.servers {
+		if s.MatchString(server) {
+			return true
+		}
+	}
+	return false
+}
+
+//grants returns true if the rule covers the whole permission
+func (r rule) grants(p Permission) bool {
+	if !r.levels[p.Level] || !r.matchesServer(p.Server) {
+		return false
+	}
+	if r.dbFrom != AllDatabases && (p.DB == AllDatabases || p.DB < r.dbFrom || p.DB > r.dbTo) {
+		return false
+	}
+	if r.keys != nil && (p.Key == AllKeys || !r.keys.MatchString(p.Key)) {
+		return false
+	}
+	return true
+}
+
+//grantsSome returns true if the rule covers the permission for some keys, and for some databases if it's given for AllDatabases
+func (r rule) grantsSome(p Permission) bool {
+	if !r.levels[p.Level] || !r.matchesServer(p.Server) {
+		return false
+	}
+	if r.dbFrom != AllDatabases && p.DB != AllDatabases && (p.DB < r.dbFrom || p.DB > r.dbTo) {
+		return false
+	}
+	return true
+}
+
+//userRules returns rules of all roles bound to the user identity or the user groups
+func userRules(user User) []rule {
+	var result []rule
+	for _, role := range userRoles[user.ID()] {
+		result = append(result, roles[role]...)
+	}
+	for _, group := range user.Groups {
+		for _, role := range groupRoles[group] {
+			result = append(result, roles[role]...)
+		}
+	}
+	return result
+}
+
+//rbacUser returns a user whose roles should be checked, false is returned if access control doesn't apply to the request
+func rbacUser(r *http.Request) (User, bool) {
+	if len(roles) == 0 {
+		return User{}, false
+	}
+	return RequestUser(r)
+}
+
+//Authorize returns AccessDeniedError if request user roles don't grant the whole permission
+//any request is authorized if authentication is off or no roles are configured
+func Authorize(r *http.Request, p Permission) error {
+	user, ok := rbacUser(r)
+	if !ok {
+		return nil
+	}
+
+	for _, rule := range userRules(user) {
+		if rule.grants(p) {
+			return nil
+		}
+	}
+	return AccessDeniedError{User: user.ID(), Permission: p}
+}
+
+//AuthorizeAny returns AccessDeniedError if request user roles don't grant the permission for any key
+//it's used for requests which results are filtered with KeyFilter
+func AuthorizeAny(r *http.Request, p Permission) error {
+	user, ok := rbacUser(r)
+	if !ok {
+		return nil
+	}
+
+	for _, rule := range userRules(user) {
+		if rule.grantsSome(p) {
+			return nil
+		}
+	}
+	return AccessDeniedError{User: user.ID(), Permission: p}
+}
+
+//KeyFilter returns a function telling if request user can read a key, nil is returned if the user can read any key
+func KeyFilter(r *http.Request, server string, db int) func(key string) bool {
+	user, ok := rbacUser(r)
+	if !ok {
+		return nil
+	}
+
+	rules := userRules(user)
+	if Authorize(r, Permission{Server: server, DB: db, Key: AllKeys, Level: redis.AccessRead}) == nil {
+		return nil
+	}
+
+	return func(key string) bool {
+		p := Permission{Server: server, DB: db, Key: key, Level: redis.AccessRead}
+		for _, rule := range rules {
+			if rule.grants(p) {
+				return true
+			}
+		}
+		return false
+	}
+}
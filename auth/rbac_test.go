@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sad0vnikov/radish/config"
+	"github.com/sad0vnikov/radish/redis"
+)
+
+func initTestRoles(t *testing.T) {
+	err := Init(config.AuthConfig{
+		//SHA-256 of 'ci-token'
+		APITokens:  []config.APIToken{{Name: "ci", TokenSHA256: "948b8c2427cd29047839b8e4a27a08763f8befbafa86be5cce8e46217d75e58a"}},
+		SessionTTL: 60,
+		Roles: []config.Role{
+			{Name: "support", Rules: []config.PermissionRule{
+				{Servers: []string{"prod"}, Verbs: []string{"read"}},
+				{Servers: []string{"prod"}, Databases: "2", Keys: "session:*", Verbs: []string{"write"}},
+			}},
+			{Name: "cache-admin", Rules: []config.PermissionRule{
+				{Servers: []string{"cache-*"}, Databases: "0-3", Verbs: []string{"read", "write", "admin"}},
+			}},
+			{Name: "session-viewer", Rules: []config.PermissionRule{
+				{Servers: []string{"prod"}, Databases: "2", Keys: "session:*", Verbs: []string{"read"}},
+			}},
+		},
+		RoleBindings: []config.RoleBinding{
+			{Role: "support", Users: []string{"alice"}},
+			{Role: "cache-admin", Groups: []string{"admins"}},
+			{Role: "session-viewer", Users: []string{"token:ci"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuthorizingWithRoles(t *testing.T) {
+	initTestRoles(t)
+	defer Init(config.AuthConfig{})
+
+	alice := withUser(httptest.NewRequest("GET", "/", nil), User{Name: "alice", Provider: "local"})
+	bob := withUser(httptest.NewRequest("GET", "/", nil), User{Name: "bob", Provider: "oidc", Groups: []string{"admins"}})
+
+	cases := []struct {
+		user    string
+		p       Permission
+		allowed bool
+	}{
+		{"alice", Permission{Server: "prod", DB: 5, Key: "user:1", Level: redis.AccessRead}, true},
+		{"alice", Permission{Server: "prod", DB: 2, Key: "session:1", Level: redis.AccessWrite}, true},
+		{"alice", Permission{Server: "prod", DB: 2, Key: "user:1", Level: redis.AccessWrite}, false},
+		{"alice", Permission{Server: "prod", DB: 3, Key: "session:1", Level: redis.AccessWrite}, false},
+		{"alice", Permission{Server: "prod", DB: 2, Key: AllKeys, Level: redis.AccessWrite}, false},
+		{"alice", Permission{Server: "staging", DB: 0, Key: "user:1", Level: redis.AccessRead}, false},
+		{"bob", Permission{Server: "cache-eu", DB: 3, Key: AllKeys, Level: redis.AccessAdmin}, true},
+		{"bob", Permission{Server: "cache-eu", DB: AllDatabases, Key: AllKeys, Level: redis.AccessAdmin}, false},
+		{"bob", Permission{Server: "prod", DB: 0, Key: "user:1", Level: redis.AccessRead}, false},
+	}
+	for _, c := range cases {
+		r := alice
+		if c.user == "bob" {
+			r = bob
+		}
+		err := Authorize(r, c.p)
+		if c.allowed && err != nil {
+			t.Errorf("expected %v to be allowed %+v, got %v", c.user, c.p, err)
+		}
+		if _, denied := err.(AccessDeniedError); !c.allowed && !denied {
+			t.Errorf("expected %v to be denied %+v, got %v", c.user, c.p, err)
+		}
+	}
+
+	nobody := withUser(httptest.NewRequest("GET", "/", nil), User{Name: "nobody", Provider: "local"})
+	if err := AuthorizeAny(nobody, Permission{Server: "prod", DB: AllDatabases, Level: redis.AccessRead}); err == nil {
+		t.Errorf("expected a user without roles to be denied")
+	}
+
+	oidcAlice := withUser(httptest.NewRequest("GET", "/", nil), User{Name: "alice", Provider: "oidc"})
+	if err := Authorize(oidcAlice, Permission{Server: "prod", DB: 5, Key: "user:1", Level: redis.AccessRead}); err == nil {
+		t.Errorf("expected an OIDC user not to get roles bound to a local user with the same name")
+	}
+}
+
+func TestFilteringKeysWithRoles(t *testing.T) {
+	initTestRoles(t)
+	defer Init(config.AuthConfig{})
+
+	r := httptest.NewRequest("GET", "/api/v1/servers/prod/keys?db=2", nil)
+	r.Header.Set("Authorization", "Bearer ci-token")
+	r, err := Authenticate(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AuthorizeAny(r, Permission{Server: "prod", DB: 2, Level: redis.AccessRead}); err != nil {
+		t.Errorf("expected listing keys to be allowed, got %v", err)
+	}
+	if err := AuthorizeAny(r, Permission{Server: "prod", DB: 1, Level: redis.AccessRead}); err == nil {
+		t.Errorf("expected listing keys of db 1 to be denied")
+	}
+
+	filter := KeyFilter(r, "prod", 2)
+	if filter == nil {
+		t.Fatal("expected a key filter")
+	}
+	if !filter("session:1") || filter("user:1") {
+		t.Errorf("session-viewer should see session:1 and shouldn't see user:1")
+	}
+
+	alice := withUser(httptest.NewRequest("GET", "/", nil), User{Name: "alice", Provider: "local"})
+	if KeyFilter(alice, "prod", 2) != nil {
+		t.Errorf("expected no key filter for a user reading all keys")
+	}
+}
+
+func TestInvalidRoles(t *testing.T) {
+	defer Init(config.AuthConfig{})
+
+	invalid := []config.AuthConfig{
+		{Roles: []config.Role{{Name: "r", Rules: []config.PermissionRule{{Verbs: []string{"delete"}}}}}},
+		{Roles: []config.Role{{Name: "r", Rules: []config.PermissionRule{{Databases: "3-1", Verbs: []string{"read"}}}}}},
+		{RoleBindings: []config.RoleBinding{{Role: "unknown", Users: []string{"alice"}}}},
+	}
+	for _, c := range invalid {
+		if err := Init(c); err == nil {
+			t.Errorf("expected error for config %+v", c)
+		}
+	}
+}
@@ -8,8 +8,127 @@ import (
 type Config struct {
 	Servers   map[string]redis.Server
 	URLPrefix string
+	//MonitoringInterval is a period of servers load sampling in seconds
+	MonitoringInterval int
+	//MonitoringHistorySize is a count of load samples kept for every server
+	MonitoringHistorySize int
+	//LatencyProbeInterval is a period of servers PING round-trip probing in seconds
+	LatencyProbeInterval int
+	//DataDir is a directory where Radish keeps its own data, e.g. saved scripts
+	DataDir string
+	Auth    AuthConfig
+	Audit   AuditConfig
+	//Snapshots configures key states saved before changes, so they can be undone
+	Snapshots SnapshotsConfig
 }
 
+//SnapshotsConfig configures the history of key snapshots kept in memory
+type SnapshotsConfig struct {
+	//HistorySize is a count of the latest snapshots kept for all servers
+	HistorySize int
+	//MaxValueSize is a serialized key size in bytes up to which values are saved, bigger keys can't be restored
+	//keys taking more memory are not serialized at all and have no ETags
+	MaxValueSize int
+}
+
+//AuditConfig configures the log of changes made with Radish, the log is kept in DataDir
+type AuditConfig struct {
+	//MaxFileSize is a size of the log file in megabytes it's rotated at
+	MaxFileSize int
+	//MaxFiles is a count of rotated log files kept
+	MaxFiles int
+	//MaxValueSize is a serialized size in bytes key values are logged in full up to, only digests of bigger values are logged
+	MaxValueSize int
+}
+
+//AuthConfig configures Radish users authentication, anyone can use Radish if no users and API tokens are configured
+type AuthConfig struct {
+	Users     []LocalUser
+	APITokens []APIToken
+	//SessionTTL is a lifetime of a login session in minutes
+	SessionTTL int
+	//OIDC turns on OpenID Connect single sign-on if it's set
+	OIDC *OIDCConfig
+	//Roles restrict what users can do, any authenticated user can do anything if no roles are configured
+	Roles []Role
+	//RoleBindings assign roles to users and groups, users without roles can't access any server
+	RoleBindings []RoleBinding
+}
+
+//Role is a named set of permission rules
+type Role struct {
+	Name  string
+	Rules []PermissionRule
+}
+
+//PermissionRule grants verbs on keys of servers databases, empty fields match anything
+type PermissionRule struct {
+	//Servers are server names, '*' and '?' wildcards are supported
+	Servers []string
+	//Databases is a single database number or an inclusive range like '0-3'
+	Databases string
+	//Keys is a keys glob like 'session:*', operations on a whole database are granted only by rules without Keys
+	Keys string
+	//Verbs are 'read', 'write' and 'admin', every verb should be granted explicitly
+	Verbs []string
+}
+
+//RoleBinding assigns a role to users and to members of groups
+//local users are given by name, users of other providers by name prefixed with the provider, e.g. 'token:ci' or 'oidc:alice'
+type RoleBinding struct {
+	Role   string
+	Users  []string
+	Groups []string
+}
+
+//OIDCConfig configures OpenID Connect authorization code flow
+type OIDCConfig struct {
+	//Issuer is an identity provider URL its discovery document is loaded from
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	//RedirectURL is Radish OIDC callback URL registered at the identity provider
+	RedirectURL string
+	//Scopes are requested in addition to 'openid'
+	Scopes []string
+	//UsernameClaim is an ID token claim used as Radish user name, 'sub' by default
+	//claims like 'preferred_username' or 'email' should only be used if the identity provider never reassigns them
+	UsernameClaim string
+	//GroupsClaim is an ID token claim listing user groups, 'groups' by default
+	GroupsClaim string
+	//GroupMapping renames identity provider groups to Radish groups, only mapped groups are kept if it's set
+	GroupMapping map[string]string
+	//AllowedGroups are Radish groups allowed to log in, any user can log in if it's empty
+	AllowedGroups []string
+}
+
+//LocalUser is a user authenticated with a password stored in Radish config
+type LocalUser struct {
+	Name string
+	//PasswordHash is a bcrypt hash of the user password
+	PasswordHash string
+}
+
+//APIToken is a static token scripts authenticate with sending 'Authorization: Bearer <token>' header
+type APIToken struct {
+	Name string
+	//TokenSHA256 is a hex-encoded SHA-256 digest of the token
+	TokenSHA256 string
+}
+
+const (
+	defaultMonitoringInterval    = 5
+	defaultMonitoringHistorySize = 720
+	defaultLatencyProbeInterval  = 1
+	defaultDataDir               = "data"
+	defaultSessionTTL            = 12 * 60
+	defaultAuditMaxFileSize      = 10
+	defaultAuditMaxFiles         = 5
+	defaultAuditMaxValueSize     = 1024
+	defaultSnapshotHistorySize   = 100
+	defaultSnapshotMaxValueSize  = 1024 * 1024
+)
+
 //Loader is an interface for configuration loading logic
 type Loader interface {
 	Load() (Config, error)
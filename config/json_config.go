@@ -15,8 +15,15 @@ type JSONFileConfigLoader struct {
 
 //JSONContents represents JSON file format
 type JSONContents struct {
-	Servers   []redis.Server
-	URLPrefix string
+	Servers               []redis.Server
+	URLPrefix             string
+	MonitoringInterval    int
+	MonitoringHistorySize int
+	LatencyProbeInterval  int
+	DataDir               string
+	Auth                  AuthConfig
+	Audit                 AuditConfig
+	Snapshots             SnapshotsConfig
 }
 
 //Load config data from JSON file
@@ -47,9 +54,69 @@ func fillConfig(contents JSONContents) (Config, error) {
 		if _, prs := config.Servers[server.Name]; prs == true {
 			return config, errors.New("server names should be unique in your config.json")
 		}
+		switch server.Mode {
+		case "":
+			server.Mode = redis.ModeReadWrite
+		case redis.ModeReadWrite, redis.ModeReadOnly, redis.ModeProtected:
+		default:
+			return config, errors.New("server " + server.Name + " has unknown mode " + server.Mode +
+				", expected " + redis.ModeReadWrite + ", " + redis.ModeReadOnly + " or " + redis.ModeProtected)
+		}
+		if server.Trash != nil {
+			trash := *server.Trash
+			if trash.Mode != redis.TrashServer && trash.Mode != redis.TrashLocal {
+				return config, errors.New("server " + server.Name + " has unknown trash mode " + trash.Mode +
+					", expected " + redis.TrashServer + " or " + redis.TrashLocal)
+			}
+			if len(trash.Prefix) == 0 {
+				trash.Prefix = redis.DefaultTrashPrefix
+			}
+			if trash.RetentionHours <= 0 {
+				trash.RetentionHours = redis.DefaultTrashRetentionHours
+			}
+			server.Trash = &trash
+		}
 		config.Servers[server.Name] = server
 	}
 	config.URLPrefix = contents.URLPrefix
 
+	config.MonitoringInterval = contents.MonitoringInterval
+	if config.MonitoringInterval <= 0 {
+		config.MonitoringInterval = defaultMonitoringInterval
+	}
+	config.MonitoringHistorySize = contents.MonitoringHistorySize
+	if config.MonitoringHistorySize <= 0 {
+		config.MonitoringHistorySize = defaultMonitoringHistorySize
+	}
+	config.LatencyProbeInterval = contents.LatencyProbeInterval
+	if config.LatencyProbeInterval <= 0 {
+		config.LatencyProbeInterval = defaultLatencyProbeInterval
+	}
+	config.DataDir = contents.DataDir
+	if len(config.DataDir) == 0 {
+		config.DataDir = defaultDataDir
+	}
+	config.Auth = contents.Auth
+	if config.Auth.SessionTTL <= 0 {
+		config.Auth.SessionTTL = defaultSessionTTL
+	}
+	config.Audit = contents.Audit
+	if config.Audit.MaxFileSize <= 0 {
+		config.Audit.MaxFileSize = defaultAuditMaxFileSize
+	}
+	if config.Audit.MaxFiles <= 0 {
+		config.Audit.MaxFiles = defaultAuditMaxFiles
+	}
+	if config.Audit.MaxValueSize <= 0 {
+		config.Audit.MaxValueSize = defaultAuditMaxValueSize
+	}
+	config.Snapshots = contents.Snapshots
+	if config.Snapshots.HistorySize <= 0 {
+		config.Snapshots.HistorySize = defaultSnapshotHistorySize
+	}
+	if config.Snapshots.MaxValueSize <= 0 {
+		config.Snapshots.MaxValueSize = defaultSnapshotMaxValueSize
+	}
+
 	return config, nil
 }
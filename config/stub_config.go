@@ -1,19 +1,39 @@
 package config
 
-import "github.com/sad0vnikov/radish/redis"
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sad0vnikov/radish/redis"
+)
 
 //StubConfigLoader is a stubbed config loader for testing purposes
+//DataDir overrides the default data directory, tests writing files should give it their own temporary directory
 type StubConfigLoader struct {
+	DataDir string
 }
 
 //Load func loads stubbed app config
-func (StubConfigLoader) Load() (Config, error) {
+func (l StubConfigLoader) Load() (Config, error) {
 	servers := map[string]redis.Server{}
 	servers["server1"] = redis.NewServer("server1", "127.0.0.1", 6379)
 	servers["server2"] = redis.NewServer("server2", "127.0.0.1", 6380)
 	servers["server3"] = redis.NewServer("server3", "127.0.0.1", 6381)
 
-	config = Config{Servers: servers}
+	config = Config{
+		Servers:               servers,
+		MonitoringInterval:    defaultMonitoringInterval,
+		MonitoringHistorySize: defaultMonitoringHistorySize,
+		LatencyProbeInterval:  defaultLatencyProbeInterval,
+		Auth:                  AuthConfig{SessionTTL: defaultSessionTTL},
+		Audit:                 AuditConfig{MaxFileSize: defaultAuditMaxFileSize, MaxFiles: defaultAuditMaxFiles, MaxValueSize: defaultAuditMaxValueSize},
+		Snapshots:             SnapshotsConfig{HistorySize: defaultSnapshotHistorySize, MaxValueSize: defaultSnapshotMaxValueSize},
+		DataDir:               filepath.Join(os.TempDir(), "radish-test-data"),
+	}
+
+	if len(l.DataDir) > 0 {
+		config.DataDir = l.DataDir
+	}
 
 	return config, nil
 }
@@ -1,6 +1,10 @@
 package helpers
 
-import "strconv"
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 //SizeInBytesToHumanReadable converts bytes count to human-readable string
 //e.g. SizeInBytesToHumanReadable(0) = 0B
@@ -18,3 +22,12 @@ func SizeInBytesToHumanReadable(size int64) string {
 
 	return humanSize
 }
+
+//GlobToRegexp converts a glob with '*' and '?' wildcards to a regular expression matching whole strings
+//e.g. GlobToRegexp("session:*") matches "session:1" and doesn't match "user:session:1"
+func GlobToRegexp(glob string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(glob)
+	expr = strings.Replace(expr, `\*`, ".*", -1)
+	expr = strings.Replace(expr, `\?`, ".", -1)
+	return regexp.MustCompile("^" + expr + "$")
+}
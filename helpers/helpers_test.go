@@ -25,3 +25,17 @@ func TestConvertingSizeInBytesToHumanReadable(t *testing.T) {
 		t.Errorf("error asserting that SizeInBytesToHumanReadable(1024*1024*1024) = '1GB', got result %s", r)
 	}
 }
+
+func TestConvertingGlobToRegexp(t *testing.T) {
+	r := GlobToRegexp("session:*.v?")
+	for _, s := range []string{"session:1.v2", "session:.v1", "session:a:b.v3"} {
+		if !r.MatchString(s) {
+			t.Errorf("expected session:*.v? to match %s", s)
+		}
+	}
+	for _, s := range []string{"user:session:1.v2", "session:1xv2", "session:1.v22"} {
+		if r.MatchString(s) {
+			t.Errorf("expected session:*.v? not to match %s", s)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis"
+)
+
+//canReadServer returns true if request user roles allow reading any data of a server
+func canReadServer(r *http.Request, serverName string) bool {
+	return auth.AuthorizeAny(r, auth.Permission{Server: serverName, DB: auth.AllDatabases, Key: auth.AllKeys, Level: redis.AccessRead}) == nil
+}
+
+//authorize returns a forbidden error if request user roles don't grant a permission
+//it's used for permissions which can't be checked by the http server, e.g. for servers and databases given in JSON
+func authorize(r *http.Request, p auth.Permission) error {
+	err := auth.Authorize(r, p)
+	if err != nil {
+		return responds.NewForbiddenError(err.Error())
+	}
+	return nil
+}
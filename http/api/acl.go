@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+const defaultACLLogCount = 10
+
+//GetACLUsers returns server ACL users with their rules
+func GetACLUsers(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := db.GetACLUsers(GetParam("server", r))
+	if err != nil {
+		return nil, aclError(err)
+	}
+
+	return users, nil
+}
+
+//GetACLUser returns an ACL user description
+func GetACLUser(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "user"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := db.GetACLUser(GetParam("server", r), GetParam("user", r))
+	if err != nil {
+		return nil, aclError(err)
+	}
+
+	return user, nil
+}
+
+type setACLUserJSONRequest struct {
+	Rules []string
+}
+
+//SetACLUser creates an ACL user or modifies an existing one applying rules given in JSON `Rules` param
+func SetACLUser(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "user"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+	userName := GetParam("user", r)
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq setACLUserJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+
+	err = db.SetACLUser(serverName, userName, bodyReq.Rules)
+	if err != nil {
+		return nil, aclError(err)
+	}
+
+	user, err := db.GetACLUser(serverName, userName)
+	if err != nil {
+		return nil, aclError(err)
+	}
+
+	return user, nil
+}
+
+//DeleteACLUser deletes an ACL user
+func DeleteACLUser(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "user"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.DeleteACLUser(GetParam("server", r), GetParam("user", r))
+	if err != nil {
+		return nil, aclError(err)
+	}
+
+	return "", nil
+}
+
+//GetACLLog returns 'count' latest ACL log entries
+func GetACLLog(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	count := defaultACLLogCount
+	if countParam := GetParam("count", r); len(countParam) > 0 {
+		count, err = strconv.Atoi(countParam)
+		if err != nil || count <= 0 {
+			return nil, responds.NewBadRequestError("'count' should be a positive number")
+		}
+	}
+
+	entries, err := db.GetACLLog(GetParam("server", r), count)
+	if err != nil {
+		return nil, aclError(err)
+	}
+
+	return entries, nil
+}
+
+//ResetACLLog clears server ACL log
+func ResetACLLog(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.ResetACLLog(GetParam("server", r))
+	if err != nil {
+		return nil, aclError(err)
+	}
+
+	return "", nil
+}
+
+type aclDryRunJSONRequest struct {
+	Command string
+	Args    []string
+}
+
+//ACLDryRun checks if a user may execute a command given as a command line in JSON `Command` param or as JSON `Args` list
+func ACLDryRun(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "user"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq aclDryRunJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+
+	args := bodyReq.Args
+	if len(args) == 0 {
+		args, err = db.ParseCommandLine(bodyReq.Command)
+		if err != nil {
+			return nil, responds.NewBadRequestError(err.Error())
+		}
+	}
+	if len(args) == 0 {
+		return nil, responds.NewBadRequestError("JSON `Command` or `Args` param is required")
+	}
+
+	result, err := db.ACLDryRun(GetParam("server", r), GetParam("user", r), args)
+	if err != nil {
+		return nil, aclError(err)
+	}
+
+	return result, nil
+}
+
+func aclError(err error) error {
+	if _, ok := err.(db.ACLUserNotFoundError); ok {
+		return responds.NewNotFoundError(err.Error())
+	}
+	return commandPolicyError(err)
+}
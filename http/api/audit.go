@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sad0vnikov/radish/audit"
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis"
+)
+
+const (
+	defaultAuditEntriesCount = 100
+	maxAuditEntriesCount     = 1000
+)
+
+//GetAuditLog returns the latest audit log entries
+//entries can be filtered with 'server', 'key' pattern, 'user' and 'from' and 'to' unix timestamps params,
+//entries about servers and keys the user can't read are not returned
+func GetAuditLog(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	q := audit.Query{
+		Server:     GetParam("server", r),
+		KeyPattern: GetParam("key", r),
+		User:       GetParam("user", r),
+		Limit:      defaultAuditEntriesCount,
+	}
+
+	var err error
+	for param, value := range map[string]*int64{"from": &q.From, "to": &q.To} {
+		if s := GetParam(param, r); len(s) > 0 {
+			*value, err = strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, responds.NewBadRequestError("'" + param + "' should be a unix timestamp")
+			}
+		}
+	}
+	if s := GetParam("limit", r); len(s) > 0 {
+		q.Limit, err = strconv.Atoi(s)
+		if err != nil || q.Limit <= 0 || q.Limit > maxAuditEntriesCount {
+			return nil, responds.NewBadRequestError("'limit' should be a number from 1 to " + strconv.Itoa(maxAuditEntriesCount))
+		}
+	}
+
+	return audit.Search(q, func(entry audit.Entry) bool {
+		if len(entry.Server) == 0 {
+			return true
+		}
+		p := auth.Permission{Server: entry.Server, DB: auth.AllDatabases, Key: entry.Key, Level: redis.AccessRead}
+		if entry.DB != nil {
+			p.DB = int(*entry.DB)
+		}
+		return auth.Authorize(r, p) == nil
+	})
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/http/server"
+	"github.com/sad0vnikov/radish/logger"
+)
+
+type loginJSONRequest struct {
+	Name     string
+	Password string
+}
+
+type currentUserResponse struct {
+	AuthEnabled bool
+	OIDCEnabled bool
+	User        *auth.User `json:",omitempty"`
+	CSRFToken   string     `json:",omitempty"`
+}
+
+//Login checks user credentials and starts a session, the session ID is sent in a cookie
+//the returned CSRF token should be sent in X-CSRF-Token header of requests changing data
+func Login(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq loginJSONRequest
+	err := decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+
+	session, err := auth.Login(bodyReq.Name, bodyReq.Password)
+	if err == auth.ErrInvalidCredentials {
+		return nil, responds.NewUnauthorizedError(err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	auth.SetSessionCookie(w, r, session)
+	_, oidcEnabled := auth.OIDC()
+	return currentUserResponse{AuthEnabled: true, OIDCEnabled: oidcEnabled, User: &session.User, CSRFToken: session.CSRFToken}, nil
+}
+
+//Logout ends the current session
+func Logout(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	if session, ok := auth.RequestSession(r); ok {
+		auth.Logout(session.ID)
+	}
+	auth.ClearSessionCookie(w)
+
+	return "", nil
+}
+
+//GetCurrentUser returns the authenticated user and session CSRF token
+func GetCurrentUser(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	_, oidcEnabled := auth.OIDC()
+	response := currentUserResponse{AuthEnabled: auth.Enabled(), OIDCEnabled: oidcEnabled}
+	if user, ok := auth.RequestUser(r); ok {
+		response.User = &user
+	}
+	if session, ok := auth.RequestSession(r); ok {
+		response.CSRFToken = session.CSRFToken
+	}
+
+	return response, nil
+}
+
+type oidcLoginResponse struct {
+	URL string
+}
+
+//StartOIDCLogin returns an identity provider URL the user should be redirected to for OpenID Connect login
+func StartOIDCLogin(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	provider, ok := auth.OIDC()
+	if !ok {
+		return nil, responds.NewNotFoundError("OpenID Connect is not configured")
+	}
+
+	url, state, err := provider.AuthCodeURL()
+	if err != nil {
+		return nil, err
+	}
+	auth.SetOIDCStateCookie(w, r, state)
+
+	return oidcLoginResponse{URL: url}, nil
+}
+
+//FinishOIDCLogin handles identity provider callback, starts a session and redirects the user to Radish panel
+func FinishOIDCLogin(w http.ResponseWriter, r *http.Request) error {
+	provider, ok := auth.OIDC()
+	if !ok {
+		return responds.NewNotFoundError("OpenID Connect is not configured")
+	}
+
+	if errParam := GetParam("error", r); len(errParam) > 0 {
+		return responds.NewUnauthorizedError("identity provider returned an error: " + errParam + " " + GetParam("error_description", r))
+	}
+
+	err := CheckRequiredParams([]string{"state", "code"}, r)
+	if err != nil {
+		return err
+	}
+
+	var browserState string
+	if cookie, err := r.Cookie(auth.OIDCStateCookieName); err == nil {
+		browserState = cookie.Value
+	}
+	auth.ClearOIDCStateCookie(w)
+
+	user, err := provider.Exchange(r.Context(), GetParam("state", r), browserState, GetParam("code", r))
+	if err != nil {
+		logger.Info("OIDC login failed: " + err.Error())
+		if _, ok := err.(auth.GroupNotAllowedError); ok {
+			return responds.NewForbiddenError(err.Error())
+		}
+		return responds.NewUnauthorizedError("OpenID Connect login failed")
+	}
+
+	session, err := auth.CreateSession(user)
+	if err != nil {
+		return err
+	}
+
+	auth.SetSessionCookie(w, r, session)
+	http.Redirect(w, r, server.GetURLPrefix(), http.StatusFound)
+	return nil
+}
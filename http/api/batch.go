@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sad0vnikov/radish/audit"
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+const maxBatchOperations = 1000
+
+type batchJSONRequest struct {
+	Transaction bool
+	Operations  []db.BatchOperation
+}
+
+type batchResponse struct {
+	Results []db.BatchResult
+}
+
+//RunBatch executes a list of key changes in the given order, pipelined or within a transaction if 'Transaction' JSON param is true
+//every operation gets its own result, so a failed operation doesn't stop the others
+func RunBatch(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, responds.NewBadRequestError(err.Error())
+	}
+	serverName := GetParam("server", r)
+	var dbNum uint8
+	dbNum, err = GetParamUint8("db", r)
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq batchJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+	if len(bodyReq.Operations) == 0 {
+		return nil, responds.NewBadRequestError("JSON `Operations` param is missing")
+	}
+	if len(bodyReq.Operations) > maxBatchOperations {
+		return nil, responds.NewBadRequestError(fmt.Sprintf("a batch can't have more than %v operations", maxBatchOperations))
+	}
+
+	err = db.ValidateBatch(bodyReq.Operations)
+	if err != nil {
+		return nil, responds.NewBadRequestError(err.Error())
+	}
+	for _, op := range bodyReq.Operations {
+		err = authorize(r, auth.Permission{Server: serverName, DB: int(dbNum), Key: op.Key, Level: redis.AccessWrite})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ops := make([]string, len(bodyReq.Operations))
+	for i, op := range bodyReq.Operations {
+		audit.AddKeys(r, op.Key)
+		ops[i] = op.Op
+	}
+	audit.AddDetail(r, "Operations", ops)
+
+	results, err := db.RunBatch(serverName, dbNum, bodyReq.Operations, bodyReq.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return batchResponse{Results: results}, nil
+}
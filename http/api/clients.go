@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+type clientsResponse struct {
+	Clients      []db.ClientInfo
+	TotalCount   int
+	MatchedCount int
+}
+
+//GetClients returns a list of clients connected to a server
+//the list can be filtered with 'addr', 'name', 'flags', 'cmd', 'user', 'db' params
+//and sorted with 'sortBy' and 'order' params
+func GetClients(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.ClientsQuery{
+		Addr:   GetParam("addr", r),
+		Name:   GetParam("name", r),
+		Flags:  GetParam("flags", r),
+		Cmd:    GetParam("cmd", r),
+		User:   GetParam("user", r),
+		SortBy: GetParam("sortBy", r),
+		Desc:   GetParam("order", r) == "desc",
+	}
+	if dbParam := GetParam("db", r); len(dbParam) > 0 {
+		dbNum, err := strconv.ParseInt(dbParam, 10, 64)
+		if err != nil {
+			return nil, responds.NewBadRequestError("'db' should be a number")
+		}
+		query.DB = &dbNum
+	}
+
+	clients, err := db.GetClients(GetParam("server", r))
+	if err != nil {
+		return nil, err
+	}
+
+	matched, err := db.QueryClients(clients, query)
+	if err != nil {
+		return nil, responds.NewBadRequestError(err.Error())
+	}
+
+	return clientsResponse{Clients: matched, TotalCount: len(clients), MatchedCount: len(matched)}, nil
+}
+
+type killClientsJSONRequest struct {
+	ID   int64
+	Addr string
+	User string
+}
+
+type killClientsResponse struct {
+	KilledCount int64
+}
+
+//KillClients closes connections of clients with given ID, address or user
+func KillClients(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	err = CheckConfirmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq killClientsJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+	if bodyReq.ID == 0 && len(bodyReq.Addr) == 0 && len(bodyReq.User) == 0 {
+		return nil, responds.NewBadRequestError("one of JSON `ID`, `Addr` or `User` params is required")
+	}
+
+	filter := db.ClientKillFilter{ID: bodyReq.ID, Addr: bodyReq.Addr, User: bodyReq.User}
+	killed, err := db.KillClients(GetParam("server", r), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return killClientsResponse{KilledCount: killed}, nil
+}
+
+type pauseClientsJSONRequest struct {
+	Timeout int64
+	Mode    string
+}
+
+//PauseClients suspends server clients for a given timeout in milliseconds
+func PauseClients(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	err = CheckConfirmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq pauseClientsJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+	if bodyReq.Timeout <= 0 {
+		return nil, responds.NewBadRequestError("JSON `Timeout` param should be a positive number of milliseconds")
+	}
+	if len(bodyReq.Mode) > 0 && bodyReq.Mode != "ALL" && bodyReq.Mode != "WRITE" {
+		return nil, responds.NewBadRequestError("JSON `Mode` param should be either ALL or WRITE")
+	}
+
+	err = db.PauseClients(GetParam("server", r), bodyReq.Timeout, bodyReq.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return "", nil
+}
+
+//UnpauseClients resumes paused server clients
+func UnpauseClients(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	err = CheckConfirmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.UnpauseClients(GetParam("server", r))
+	if err != nil {
+		return nil, err
+	}
+
+	return "", nil
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sad0vnikov/radish/audit"
+	"github.com/sad0vnikov/radish/http/responds"
+	rd "github.com/sad0vnikov/radish/redis"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+const confirmationTokenTTL = time.Minute
+
+type pendingConfirmation struct {
+	operation string
+	expires   time.Time
+}
+
+//confirmationTokens are issued for destructive operations which are executed only when called again with the token
+type confirmationTokens struct {
+	mu      sync.Mutex
+	pending map[string]pendingConfirmation
+}
+
+func (c *confirmationTokens) issue(operation string) (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for t, p := range c.pending {
+		if now.After(p.expires) {
+			delete(c.pending, t)
+		}
+	}
+	c.pending[token] = pendingConfirmation{operation: operation, expires: now.Add(confirmationTokenTTL)}
+
+	return token, nil
+}
+
+//take checks that a token was issued for the operation and hasn't expired, a token can be used only once
+func (c *confirmationTokens) take(token, operation string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, prs := c.pending[token]
+	if !prs {
+		return false
+	}
+	delete(c.pending, token)
+
+	return p.operation == operation && time.Now().Before(p.expires)
+}
+
+var confirmations = &confirmationTokens{pending: make(map[string]pendingConfirmation)}
+
+type confirmationResponse struct {
+	Token            string
+	ExpiresInSeconds int
+	KeyspaceStat     map[string]rd.ServerKeyspaceStat
+}
+
+//confirmOperation implements two-step confirmation of destructive operations
+//the first call without 'token' param returns a token with the server keys count, the operation is confirmed when it's called again with the token
+func confirmOperation(r *http.Request, serverName, operation string) (bool, interface{}, error) {
+	err := db.CheckServerMode(serverName, rd.AccessAdmin)
+	if err != nil {
+		return false, nil, responds.NewForbiddenError(err.Error())
+	}
+
+	operation = requestUser(r) + " " + operation
+	token := GetParam("token", r)
+	if len(token) > 0 {
+		if !confirmations.take(token, operation) {
+			return false, nil, responds.NewBadRequestError("confirmation token is invalid or expired")
+		}
+		return true, nil, nil
+	}
+
+	keyspaceStat, err := db.GetServerKeyspaceStat(serverName)
+	if err != nil {
+		return false, nil, err
+	}
+
+	token, err = confirmations.issue(operation)
+	if err != nil {
+		return false, nil, err
+	}
+
+	//the operation is recorded to the audit log when it's confirmed
+	audit.Skip(r)
+	return false, confirmationResponse{
+		Token:            token,
+		ExpiresInSeconds: int(confirmationTokenTTL.Seconds()),
+		KeyspaceStat:     keyspaceStat,
+	}, nil
+}
@@ -0,0 +1,24 @@
+package api
+
+import "testing"
+
+func TestConfirmationTokens(t *testing.T) {
+	tokens := &confirmationTokens{pending: make(map[string]pendingConfirmation)}
+
+	token, err := tokens.issue("flushall server1 false")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tokens.take(token, "flushall server2 false") {
+		t.Error("a token shouldn't confirm another operation")
+	}
+
+	token, _ = tokens.issue("flushall server1 false")
+	if !tokens.take(token, "flushall server1 false") {
+		t.Error("expected the token to confirm the operation")
+	}
+	if tokens.take(token, "flushall server1 false") {
+		t.Error("a token shouldn't be used twice")
+	}
+}
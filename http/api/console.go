@@ -0,0 +1,144 @@
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sad0vnikov/radish/audit"
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+const consoleHistorySize = 100
+
+type consoleHistoryEntry struct {
+	Server  string
+	DB      uint8
+	Command string
+	Time    int64
+}
+
+//consoleHistory stores the latest console commands of every user
+type consoleHistory struct {
+	mu      sync.Mutex
+	entries map[string][]consoleHistoryEntry
+}
+
+func (h *consoleHistory) add(user string, entry consoleHistoryEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := append(h.entries[user], entry)
+	if len(entries) > consoleHistorySize {
+		entries = entries[len(entries)-consoleHistorySize:]
+	}
+	h.entries[user] = entries
+}
+
+func (h *consoleHistory) get(user, serverName string) []consoleHistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result := []consoleHistoryEntry{}
+	for _, entry := range h.entries[user] {
+		if entry.Server == serverName {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+var history = &consoleHistory{entries: make(map[string][]consoleHistoryEntry)}
+
+//requestUser returns an identity used to tell Radish users apart, it's the client IP if authentication is off
+func requestUser(r *http.Request) string {
+	if user, ok := auth.RequestUser(r); ok {
+		return user.ID()
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+type executeCommandJSONRequest struct {
+	Command string
+	Args    []string
+}
+
+type executeCommandResponse struct {
+	Reply                db.CommandReply
+	DurationMicroseconds int64
+}
+
+//ExecuteCommand runs a raw command against a server
+//the command is given either as a command line in JSON `Command` param or as JSON `Args` list
+func ExecuteCommand(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+	var dbNum uint8
+	dbNum, err = GetParamUint8("db", r)
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq executeCommandJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+
+	args := bodyReq.Args
+	if len(args) == 0 {
+		args, err = db.ParseCommandLine(bodyReq.Command)
+		if err != nil {
+			return nil, responds.NewBadRequestError(err.Error())
+		}
+	}
+	if len(args) == 0 {
+		return nil, responds.NewBadRequestError("JSON `Command` or `Args` param is required")
+	}
+
+	audit.AddDetail(r, "Command", args)
+	started := time.Now()
+	reply, err := db.ExecuteCommand(serverName, dbNum, args)
+	duration := time.Since(started)
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	command := bodyReq.Command
+	if len(command) == 0 {
+		command = db.FormatCommandLine(args)
+	}
+	history.add(requestUser(r), consoleHistoryEntry{Server: serverName, DB: dbNum, Command: command, Time: started.Unix()})
+
+	return executeCommandResponse{Reply: reply, DurationMicroseconds: duration.Nanoseconds() / 1000}, nil
+}
+
+//GetConsoleHistory returns the latest console commands the user has run against a server
+func GetConsoleHistory(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return history.get(requestUser(r), GetParam("server", r)), nil
+}
+
+func commandPolicyError(err error) error {
+	switch err.(type) {
+	case db.CommandNotAllowedError, db.CommandDisabledError:
+		return responds.NewForbiddenError(err.Error())
+	}
+	return err
+}
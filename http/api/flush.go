@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+//FlushDB removes all keys of 'db' database, the keys are freed in background if 'async' param is 'true'
+//the operation should be confirmed with a token returned by the first call
+func FlushDB(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "db"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+	dbNum, err := GetParamUint8("db", r)
+	if err != nil {
+		return nil, responds.NewBadRequestError("'db' should be a database number")
+	}
+	async := GetParam("async", r) == "true"
+
+	confirmed, resp, err := confirmOperation(r, serverName, fmt.Sprintf("flushdb %v %v %v", serverName, dbNum, async))
+	if err != nil || !confirmed {
+		return resp, err
+	}
+
+	err = db.FlushDB(serverName, dbNum, async)
+	if err != nil {
+		return nil, flushError(err)
+	}
+
+	return "", nil
+}
+
+//FlushAll removes all keys of all databases, the keys are freed in background if 'async' param is 'true'
+//the operation should be confirmed with a token returned by the first call
+func FlushAll(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+	async := GetParam("async", r) == "true"
+
+	confirmed, resp, err := confirmOperation(r, serverName, fmt.Sprintf("flushall %v %v", serverName, async))
+	if err != nil || !confirmed {
+		return resp, err
+	}
+
+	err = db.FlushAll(serverName, async)
+	if err != nil {
+		return nil, flushError(err)
+	}
+
+	return "", nil
+}
+
+//SwapDB swaps 'db' and 'with' databases
+//the operation should be confirmed with a token returned by the first call
+func SwapDB(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "db", "with"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+	first, err := GetParamUint8("db", r)
+	if err != nil {
+		return nil, responds.NewBadRequestError("'db' should be a database number")
+	}
+	second, err := GetParamUint8("with", r)
+	if err != nil {
+		return nil, responds.NewBadRequestError("'with' should be a database number")
+	}
+	err = authorize(r, auth.Permission{Server: serverName, DB: int(second), Key: auth.AllKeys, Level: redis.AccessAdmin})
+	if err != nil {
+		return nil, err
+	}
+
+	confirmed, resp, err := confirmOperation(r, serverName, fmt.Sprintf("swapdb %v %v %v", serverName, first, second))
+	if err != nil || !confirmed {
+		return resp, err
+	}
+
+	err = db.SwapDB(serverName, first, second)
+	if err != nil {
+		return nil, flushError(err)
+	}
+
+	return "", nil
+}
+
+func flushError(err error) error {
+	if _, ok := err.(db.ServerModeError); ok {
+		return responds.NewForbiddenError(err.Error())
+	}
+	return commandPolicyError(err)
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sad0vnikov/radish/audit"
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/config"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+//GetFunctions returns server function libraries with names matching 'library' param
+//libraries code is returned if 'withCode' param is 'true'
+func GetFunctions(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	libraries, err := db.GetFunctions(GetParam("server", r), GetParam("library", r), GetParam("withCode", r) == "true")
+	if err != nil {
+		return nil, functionsError(err)
+	}
+
+	return libraries, nil
+}
+
+type loadFunctionLibraryJSONRequest struct {
+	Code    string
+	Replace bool
+}
+
+//LoadFunctionLibrary loads a function library, an existing library is replaced if JSON `Replace` param is true
+func LoadFunctionLibrary(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq loadFunctionLibraryJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+	if len(bodyReq.Code) == 0 {
+		return nil, responds.NewBadRequestError("JSON `Code` param is required")
+	}
+
+	library, err := db.LoadFunctionLibrary(GetParam("server", r), bodyReq.Code, bodyReq.Replace)
+	if err != nil {
+		return nil, functionsError(err)
+	}
+
+	return library, nil
+}
+
+//DeleteFunctionLibrary deletes a function library
+func DeleteFunctionLibrary(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "library"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.DeleteFunctionLibrary(GetParam("server", r), GetParam("library", r))
+	if err != nil {
+		return nil, functionsError(err)
+	}
+
+	return "", nil
+}
+
+type callFunctionJSONRequest struct {
+	Keys     []string
+	Args     []string
+	ReadOnly bool
+}
+
+//CallFunction invokes a function with FCALL, FCALL_RO is used if JSON `ReadOnly` param is true
+func CallFunction(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "function"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+	var dbNum uint8
+	dbNum, err = GetParamUint8("db", r)
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq callFunctionJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+
+	audit.AddKeys(r, bodyReq.Keys...)
+	audit.AddDetail(r, "Args", bodyReq.Args)
+	started := time.Now()
+	reply, err := db.CallFunction(serverName, dbNum, GetParam("function", r), bodyReq.Keys, bodyReq.Args, bodyReq.ReadOnly)
+	duration := time.Since(started)
+	if err != nil {
+		return nil, functionsError(err)
+	}
+
+	return executeCommandResponse{Reply: reply, DurationMicroseconds: duration.Nanoseconds() / 1000}, nil
+}
+
+type copyFunctionsJSONRequest struct {
+	To     string
+	Policy string
+}
+
+//CopyFunctions copies all function libraries to a server given in JSON `To` param
+//JSON `Policy` param is one of APPEND (default), REPLACE or FLUSH
+func CopyFunctions(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq copyFunctionsJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+	if _, ok := config.Get().Servers[bodyReq.To]; !ok {
+		return nil, responds.NewBadRequestError("JSON `To` param should be a configured server name")
+	}
+
+	err = authorize(r, auth.Permission{Server: bodyReq.To, DB: auth.AllDatabases, Key: auth.AllKeys, Level: redis.AccessAdmin})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.CheckServerMode(bodyReq.To, redis.AccessAdmin)
+	if err != nil {
+		return nil, responds.NewForbiddenError(err.Error())
+	}
+
+	err = db.CopyFunctions(GetParam("server", r), bodyReq.To, bodyReq.Policy)
+	if err != nil {
+		return nil, functionsError(err)
+	}
+
+	return "", nil
+}
+
+func functionsError(err error) error {
+	switch err.(type) {
+	case db.FunctionsNotSupportedError, db.UnknownRestorePolicyError:
+		return responds.NewBadRequestError(err.Error())
+	}
+	return commandPolicyError(err)
+}
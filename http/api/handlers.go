@@ -11,6 +11,7 @@ import (
 
 	"io/ioutil"
 
+	"github.com/sad0vnikov/radish/auth"
 	"github.com/sad0vnikov/radish/http/responds"
 	"github.com/sad0vnikov/radish/http/server"
 	"github.com/sad0vnikov/radish/logger"
@@ -19,8 +20,15 @@ import (
 )
 
 //GetServersList is a http handler returning a list of avalable Redis instances
+//servers the user can't read are not listed
 func GetServersList(w http.ResponseWriter, r *http.Request) (interface{}, error) {
-	return db.GetServersWithConnectionData(), nil
+	servers := db.GetServersWithConnectionData()
+	for name := range servers {
+		if !canReadServer(r, name) {
+			delete(servers, name)
+		}
+	}
+	return servers, nil
 }
 
 type getKeysByMaskResponse struct {
@@ -84,6 +92,9 @@ func GetKeysByMask(w http.ResponseWriter, r *http.Request) (interface{}, error)
 		logger.Error(err)
 		return nil, err
 	}
+	if filter := auth.KeyFilter(r, serverName, int(dbNum)); filter != nil {
+		keys = db.FilterKeys(keys, filter)
+	}
 
 	pageOffsetStart, pageOffsetEnd, err := redis.GetPageRangeForStrings(keys, pageSize, pageNumber)
 	if err != nil {
@@ -186,7 +197,7 @@ func GetKeysSubtree(w http.ResponseWriter, r *http.Request) (interface{}, error)
 		keyPrefix = "*"
 	}
 	node := db.KeyTreeNode{Name: keyPrefix, HasChildren: true}
-	nodes, err := db.FindKeysTreeNodeChildren(serverName, dbNum, delimiter, pageSize, node)
+	nodes, err := db.FindKeysTreeNodeChildren(serverName, dbNum, delimiter, pageSize, node, auth.KeyFilter(r, serverName, int(dbNum)))
 	if err != nil {
 		logger.Error(err)
 		return nil, err
@@ -258,6 +269,15 @@ func GetKeyValues(w http.ResponseWriter, r *http.Request) (interface{}, error) {
 		return nil, responds.NewNotFoundError(fmt.Sprintf("key %v doesn't exist", keyName))
 	}
 
+	//keys too big to be serialized have no ETag
+	etag, err := db.KeyETag(serverName, dbNum, keyName)
+	if err != nil {
+		return nil, err
+	}
+	if len(etag) > 0 {
+		w.Header().Set("ETag", etag)
+	}
+
 	pageParam := r.URL.Query().Get("page")
 	pageNum := 1
 	if len(pageParam) != 0 {
@@ -387,6 +407,19 @@ func sanitizeBinaryValue(v db.RedisValue) db.RedisValue {
 	return v
 }
 
+//ifMatch returns If-Match request header, updates are applied only if it contains the current ETag of the key value
+func ifMatch(r *http.Request) string {
+	return r.Header.Get("If-Match")
+}
+
+//valueUpdateError converts an error of a conditional key update to an API error
+func valueUpdateError(err error) error {
+	if _, ok := err.(db.ETagMismatchError); ok {
+		return responds.NewPreconditionFailedError(err.Error())
+	}
+	return err
+}
+
 //DeleteKey deletes a given key
 func DeleteKey(w http.ResponseWriter, r *http.Request) (interface{}, error) {
 
@@ -403,9 +436,9 @@ func DeleteKey(w http.ResponseWriter, r *http.Request) (interface{}, error) {
 		return nil, responds.NewBadRequestError("'key' param is required")
 	}
 
-	err = db.DeleteKey(serverName, dbNum, keyName)
+	err = db.DeleteKey(serverName, dbNum, keyName, ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -447,9 +480,9 @@ func AddStringValue(w http.ResponseWriter, r *http.Request) (interface{}, error)
 		return nil, responds.NewConflictError(fmt.Sprintf("key %v already exists", keyName))
 	}
 
-	err = db.Set(serverName, dbNum, keyName, bodyReq.Value)
+	err = db.Set(serverName, dbNum, keyName, bodyReq.Value, ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -490,9 +523,9 @@ func UpdateStringValue(w http.ResponseWriter, r *http.Request) (interface{}, err
 	if len(JSONReq.Value) == 0 {
 		return nil, responds.NewBadRequestError("`Value` JSON param is missing")
 	}
-	err = db.Set(serverName, dbNum, keyName, JSONReq.Value)
+	err = db.Set(serverName, dbNum, keyName, JSONReq.Value, ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -542,9 +575,9 @@ func AddHashValue(w http.ResponseWriter, r *http.Request) (interface{}, error) {
 		return nil, responds.NewConflictError(fmt.Sprintf("key %v already exists", keyName))
 	}
 
-	err = db.SetHashKey(serverName, dbNum, keyName, hashKey, hashValue)
+	err = db.SetHashKey(serverName, dbNum, keyName, hashKey, hashValue, ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -605,9 +638,9 @@ func UpdateHashValue(w http.ResponseWriter, r *http.Request) (interface{}, error
 		newHashKey = hashKey
 	}
 
-	err = db.UpdateHashKey(serverName, dbNum, keyName, hashKey, newHashKey, hashValue)
+	err = db.UpdateHashKey(serverName, dbNum, keyName, hashKey, newHashKey, hashValue, ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -636,9 +669,9 @@ func DeleteHashValue(w http.ResponseWriter, r *http.Request) (interface{}, error
 		return nil, responds.NewNotFoundError(fmt.Sprintf("key %v doesn't exist", keyName))
 	}
 
-	err = db.DeleteHashValue(serverName, dbNum, keyName, hashKey)
+	err = db.DeleteHashValue(serverName, dbNum, keyName, hashKey, ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -670,13 +703,13 @@ func AddListValue(w http.ResponseWriter, r *http.Request) (interface{}, error) {
 	var dbNum uint8
 	dbNum, err = GetParamUint8("db", r)
 	if bodyReq.Index != nil {
-		err = db.InsertToListWithPos(GetParam("server", r), dbNum, GetParam("key", r), bodyReq.Value, *bodyReq.Index)
+		err = db.InsertToListWithPos(GetParam("server", r), dbNum, GetParam("key", r), bodyReq.Value, *bodyReq.Index, ifMatch(r))
 	} else {
-		err = db.AppendToList(GetParam("server", r), dbNum, GetParam("key", r), bodyReq.Value)
+		err = db.AppendToList(GetParam("server", r), dbNum, GetParam("key", r), bodyReq.Value, ifMatch(r))
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -717,9 +750,9 @@ func UpdateListValue(w http.ResponseWriter, r *http.Request) (interface{}, error
 
 	var dbNum uint8
 	dbNum, err = GetParamUint8("db", r)
-	err = db.UpdateListValue(GetParam("server", r), dbNum, GetParam("key", r), int(index), bodyReq.Value)
+	err = db.UpdateListValue(GetParam("server", r), dbNum, GetParam("key", r), int(index), bodyReq.Value, ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -745,9 +778,9 @@ func DeleteListValue(w http.ResponseWriter, r *http.Request) (interface{}, error
 
 	var dbNum uint8
 	dbNum, err = GetParamUint8("db", r)
-	err = db.DeleteListValue(GetParam("server", r), dbNum, GetParam("key", r), int(index))
+	err = db.DeleteListValue(GetParam("server", r), dbNum, GetParam("key", r), int(index), ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -777,9 +810,9 @@ func AddSetValue(w http.ResponseWriter, r *http.Request) (interface{}, error) {
 
 	var dbNum uint8
 	dbNum, err = GetParamUint8("db", r)
-	err = db.AddValueToSet(GetParam("server", r), dbNum, GetParam("key", r), bodyReq.Value)
+	err = db.AddValueToSet(GetParam("server", r), dbNum, GetParam("key", r), bodyReq.Value, ifMatch(r))
 	if err != nil {
-		return "", err
+		return "", valueUpdateError(err)
 	}
 
 	return "", err
@@ -809,9 +842,9 @@ func UpdateSetValue(w http.ResponseWriter, r *http.Request) (interface{}, error)
 
 	var dbNum uint8
 	dbNum, err = GetParamUint8("db", r)
-	err = db.UpdateSetValue(GetParam("server", r), dbNum, GetParam("key", r), GetParam("value", r), bodyReq.Value)
+	err = db.UpdateSetValue(GetParam("server", r), dbNum, GetParam("key", r), GetParam("value", r), bodyReq.Value, ifMatch(r))
 	if err != nil {
-		return "", err
+		return "", valueUpdateError(err)
 	}
 
 	return "", err
@@ -826,9 +859,9 @@ func DeleteSetValue(w http.ResponseWriter, r *http.Request) (interface{}, error)
 
 	var dbNum uint8
 	dbNum, err = GetParamUint8("db", r)
-	err = db.DeleteSetValue(GetParam("server", r), dbNum, GetParam("key", r), GetParam("value", r))
+	err = db.DeleteSetValue(GetParam("server", r), dbNum, GetParam("key", r), GetParam("value", r), ifMatch(r))
 	if err != nil {
-		return "", err
+		return "", valueUpdateError(err)
 	}
 
 	return "", err
@@ -859,9 +892,9 @@ func AddZSetValue(w http.ResponseWriter, r *http.Request) (interface{}, error) {
 
 	var dbNum uint8
 	dbNum, err = GetParamUint8("db", r)
-	err = db.AddZSetValue(GetParam("server", r), dbNum, GetParam("key", r), bodyReq.Value, bodyReq.Score)
+	err = db.AddZSetValue(GetParam("server", r), dbNum, GetParam("key", r), bodyReq.Value, bodyReq.Score, ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -892,9 +925,9 @@ func UpdateZSetValue(w http.ResponseWriter, r *http.Request) (interface{}, error
 
 	var dbNum uint8
 	dbNum, err = GetParamUint8("db", r)
-	err = db.UpdateZSetValueIfExists(GetParam("server", r), dbNum, GetParam("key", r), GetParam("value", r), bodyReq.Value, bodyReq.Score)
+	err = db.UpdateZSetValueIfExists(GetParam("server", r), dbNum, GetParam("key", r), GetParam("value", r), bodyReq.Value, bodyReq.Score, ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
@@ -909,9 +942,9 @@ func DeleteZSetValue(w http.ResponseWriter, r *http.Request) (interface{}, error
 
 	var dbNum uint8
 	dbNum, err = GetParamUint8("db", r)
-	err = db.DeleteZSetValue(GetParam("server", r), dbNum, GetParam("key", r), GetParam("value", r))
+	err = db.DeleteZSetValue(GetParam("server", r), dbNum, GetParam("key", r), GetParam("value", r), ifMatch(r))
 	if err != nil {
-		return nil, err
+		return nil, valueUpdateError(err)
 	}
 
 	return "", nil
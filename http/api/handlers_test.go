@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/sad0vnikov/radish/config"
@@ -52,3 +53,28 @@ func TestGettingKeys(t *testing.T) {
 	config.StubConfigLoader{}.Load()
 
 }
+
+func TestHidingServersCommandPolicies(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	defer config.StubConfigLoader{}.Load()
+	servers := config.Get().Servers
+	srv := servers["server1"]
+	srv.RenamedCommands = map[string]string{"CONFIG": "SECRET_CONFIG"}
+	srv.DeniedCommands = []string{"FLUSHALL"}
+	srv.Trash = &redis.TrashConfig{Mode: redis.TrashServer, DB: 15}
+	servers["server1"] = srv
+
+	resp, err := GetServersList(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/servers", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"SECRET_CONFIG", "FLUSHALL", "Trash"} {
+		if strings.Contains(string(body), secret) {
+			t.Errorf("got servers list %s, expected %v to be hidden", body, secret)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+type infoSectionResponse struct {
+	Section string
+	Info    interface{}
+	Raw     map[string]string
+}
+
+//GetServerInfo returns parsed INFO output for a server
+//a single section is returned if it is given in 'section' param
+func GetServerInfo(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+	section := strings.ToLower(GetParam("section", r))
+
+	info, err := db.GetServerInfo(serverName, section)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(section) == 0 {
+		return info, nil
+	}
+
+	sectionInfo, prs := info.Section(section)
+	if !prs {
+		return nil, responds.NewBadRequestError(fmt.Sprintf("unknown INFO section '%v'", section))
+	}
+
+	return infoSectionResponse{Section: section, Info: sectionInfo, Raw: info.Raw[section]}, nil
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/monitoring"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+//GetLatencyLatest returns the latest latency spikes of all server events
+func GetLatencyLatest(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := db.GetLatencyLatest(GetParam("server", r))
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return events, nil
+}
+
+//GetLatencyHistory returns latency spikes of a server event
+func GetLatencyHistory(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "event"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := db.GetLatencyHistory(GetParam("server", r), GetParam("event", r))
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return samples, nil
+}
+
+//GetLatencyDoctor returns LATENCY DOCTOR report
+func GetLatencyDoctor(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := db.GetLatencyDoctor(GetParam("server", r))
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return report, nil
+}
+
+//GetMemoryDoctor returns MEMORY DOCTOR report
+func GetMemoryDoctor(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := db.GetMemoryDoctor(GetParam("server", r))
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return report, nil
+}
+
+//GetMemoryStats returns MEMORY STATS output
+func GetMemoryStats(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := db.GetMemoryStats(GetParam("server", r))
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return stats, nil
+}
+
+//GetProbedLatency returns PING round-trip times measured by Radish
+//it helps to tell network latency from server-side stalls reported by LATENCY commands
+func GetProbedLatency(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+
+	histogram, prs := monitoring.GetLatency(serverName)
+	if !prs {
+		return nil, responds.NewNotFoundError(fmt.Sprintf("server %v not found", serverName))
+	}
+
+	return histogram.Stats(), nil
+}
+
+//ResetProbedLatency clears PING round-trip times measured by Radish
+func ResetProbedLatency(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+
+	histogram, prs := monitoring.GetLatency(serverName)
+	if !prs {
+		return nil, responds.NewNotFoundError(fmt.Sprintf("server %v not found", serverName))
+	}
+	histogram.Reset()
+
+	return "", nil
+}
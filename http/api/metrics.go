@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sad0vnikov/radish/config"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/monitoring"
+)
+
+const defaultMetricsWindow = time.Hour
+
+type metricsResponse struct {
+	Interval int
+	Samples  []monitoring.Sample
+}
+
+//GetServerMetrics returns server load history for a time window given in 'window' param, e.g. '15m'
+func GetServerMetrics(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+
+	window := defaultMetricsWindow
+	if windowParam := GetParam("window", r); len(windowParam) > 0 {
+		window, err = time.ParseDuration(windowParam)
+		if err != nil || window <= 0 {
+			return nil, responds.NewBadRequestError("'window' should be a positive duration, e.g. '15m'")
+		}
+	}
+
+	history, prs := monitoring.GetHistory(serverName)
+	if !prs {
+		return nil, responds.NewNotFoundError(fmt.Sprintf("server %v not found", serverName))
+	}
+
+	response := metricsResponse{
+		Interval: config.Get().MonitoringInterval,
+		Samples:  history.Since(time.Now().Add(-window)),
+	}
+
+	return response, nil
+}
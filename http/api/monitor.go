@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+const (
+	defaultMonitorDuration = 10 * time.Second
+	maxMonitorDuration     = time.Minute
+)
+
+//eventStream writes server-sent events, headers are sent with the first event
+type eventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	started bool
+}
+
+func (s *eventStream) send(event string, data interface{}) error {
+	if !s.started {
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("Cache-Control", "no-cache")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload)
+	if err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+//MonitorServer streams commands processed by a server as server-sent events for 'duration' seconds
+//commands can be filtered with 'command', 'key' and 'client' params
+//a summary of command frequencies and hottest keys is sent as the last 'summary' event
+//commands of all databases are streamed, so the user should be able to read every key of the server
+func MonitorServer(w http.ResponseWriter, r *http.Request) error {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return err
+	}
+	err = authorize(r, auth.Permission{Server: GetParam("server", r), DB: auth.AllDatabases, Key: auth.AllKeys, Level: redis.AccessRead})
+	if err != nil {
+		return err
+	}
+
+	duration := defaultMonitorDuration
+	if durationParam := GetParam("duration", r); len(durationParam) > 0 {
+		seconds, err := strconv.Atoi(durationParam)
+		if err != nil || seconds <= 0 {
+			return responds.NewBadRequestError("'duration' should be a positive number of seconds")
+		}
+		duration = time.Duration(seconds) * time.Second
+	}
+	if duration > maxMonitorDuration {
+		return responds.NewBadRequestError(fmt.Sprintf("'duration' can't be greater than %v seconds", maxMonitorDuration.Seconds()))
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("streaming is not supported")
+	}
+	stream := &eventStream{w: w, flusher: flusher}
+
+	filter := db.MonitorFilter{
+		Command:    GetParam("command", r),
+		KeyPattern: GetParam("key", r),
+		ClientAddr: GetParam("client", r),
+	}
+
+	summary, err := db.Monitor(GetParam("server", r), duration, filter, ":", r.Context().Done(), func(c db.MonitoredCommand) error {
+		return stream.send("command", c)
+	})
+	if err != nil && !stream.started {
+		return err
+	}
+	if err != nil {
+		return stream.send("error", err.Error())
+	}
+
+	return stream.send("summary", summary)
+}
@@ -50,3 +50,11 @@ func GetParamUint8(param string, r *http.Request) (uint8, error) {
 
 	return uint8(conv), nil
 }
+
+//CheckConfirmed returns error if a dangerous operation is not confirmed with 'confirm=true' param
+func CheckConfirmed(r *http.Request) error {
+	if GetParam("confirm", r) != "true" {
+		return responds.NewBadRequestError("the operation should be confirmed with 'confirm=true' param")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+//GetPersistenceStatus returns server RDB and AOF status
+func GetPersistenceStatus(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := db.GetPersistenceStatus(GetParam("server", r))
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+//GetLastSave returns the time of the last successful RDB save
+func GetLastSave(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	lastSave, err := db.GetLastSave(GetParam("server", r))
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return lastSave, nil
+}
+
+//StartBackgroundSave runs BGSAVE, it should be confirmed with 'confirm=true' param
+//the save is scheduled after running AOF rewrite if 'schedule' param is 'true'
+//the returned LastSave value should be passed to GetBackgroundSaveProgress as 'since' param
+func StartBackgroundSave(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	err = CheckConfirmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err := db.StartBackgroundSave(GetParam("server", r), GetParam("schedule", r) == "true")
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return operation, nil
+}
+
+//GetBackgroundSaveProgress returns progress of BGSAVE started when LASTSAVE was equal to 'since' param
+func GetBackgroundSaveProgress(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "since"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	since, err := strconv.ParseInt(GetParam("since", r), 10, 64)
+	if err != nil {
+		return nil, responds.NewBadRequestError("'since' should be a unix timestamp")
+	}
+
+	progress, err := db.GetBackgroundSaveProgress(GetParam("server", r), since)
+	if err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+//StartAOFRewrite runs BGREWRITEAOF, it should be confirmed with 'confirm=true' param
+func StartAOFRewrite(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	err = CheckConfirmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err := db.StartAOFRewrite(GetParam("server", r))
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return operation, nil
+}
+
+//GetAOFRewriteProgress returns BGREWRITEAOF progress
+func GetAOFRewriteProgress(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := db.GetAOFRewriteProgress(GetParam("server", r))
+	if err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sad0vnikov/radish/audit"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis/db"
+	"github.com/sad0vnikov/radish/storage"
+)
+
+type evalScriptJSONRequest struct {
+	Script string
+	SHA    string
+	Keys   []string
+	Args   []string
+}
+
+//EvalScript runs a Lua script given in JSON `Script` param or a cached script with JSON `SHA` param
+func EvalScript(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+	var dbNum uint8
+	dbNum, err = GetParamUint8("db", r)
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq evalScriptJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+
+	audit.AddKeys(r, bodyReq.Keys...)
+	audit.AddDetail(r, "Args", bodyReq.Args)
+	started := time.Now()
+	var reply db.CommandReply
+	switch {
+	case len(bodyReq.Script) > 0:
+		audit.AddDetail(r, "Script", bodyReq.Script)
+		reply, err = db.EvalScript(serverName, dbNum, bodyReq.Script, bodyReq.Keys, bodyReq.Args)
+	case len(bodyReq.SHA) > 0:
+		audit.AddDetail(r, "SHA", bodyReq.SHA)
+		reply, err = db.EvalScriptSHA(serverName, dbNum, bodyReq.SHA, bodyReq.Keys, bodyReq.Args)
+	default:
+		return nil, responds.NewBadRequestError("JSON `Script` or `SHA` param is required")
+	}
+	duration := time.Since(started)
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return executeCommandResponse{Reply: reply, DurationMicroseconds: duration.Nanoseconds() / 1000}, nil
+}
+
+type loadScriptJSONRequest struct {
+	Script string
+}
+
+//LoadScript loads a Lua script to the server script cache and returns its SHA1 digest
+func LoadScript(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq loadScriptJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+	if len(bodyReq.Script) == 0 {
+		return nil, responds.NewBadRequestError("JSON `Script` param is required")
+	}
+
+	sha, err := db.LoadScript(GetParam("server", r), bodyReq.Script)
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return sha, nil
+}
+
+//ScriptsExist checks if scripts with SHA1 digests given in comma-separated 'sha' param are cached by the server
+func ScriptsExist(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "sha"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	exist, err := db.ScriptsExist(GetParam("server", r), strings.Split(GetParam("sha", r), ","))
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return exist, nil
+}
+
+//FlushScripts removes all scripts from the server script cache
+//the cache is flushed asynchronously if 'async' param is 'true'
+func FlushScripts(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.FlushScripts(GetParam("server", r), GetParam("async", r) == "true")
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return "", nil
+}
+
+//GetSavedScripts returns scripts saved in Radish scripts library
+func GetSavedScripts(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return storage.GetScripts()
+}
+
+//GetSavedScript returns a saved script by name
+func GetSavedScript(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"name"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	script, err := storage.GetScript(GetParam("name", r))
+	if err != nil {
+		return nil, scriptError(err)
+	}
+
+	return script, nil
+}
+
+type saveScriptJSONRequest struct {
+	Description string
+	Body        string
+}
+
+//SaveScript adds a script to Radish scripts library or replaces a script with the same name
+func SaveScript(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"name"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq saveScriptJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+	if len(bodyReq.Body) == 0 {
+		return nil, responds.NewBadRequestError("JSON `Body` param is required")
+	}
+
+	return storage.SaveScript(storage.Script{
+		Name:        GetParam("name", r),
+		Description: bodyReq.Description,
+		Body:        bodyReq.Body,
+	})
+}
+
+//DeleteSavedScript removes a script from Radish scripts library
+func DeleteSavedScript(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"name"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = storage.DeleteScript(GetParam("name", r))
+	if err != nil {
+		return nil, scriptError(err)
+	}
+
+	return "", nil
+}
+
+type runScriptJSONRequest struct {
+	Keys []string
+	Args []string
+}
+
+//RunSavedScript runs a script from Radish scripts library against a server
+func RunSavedScript(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "name"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+	var dbNum uint8
+	dbNum, err = GetParamUint8("db", r)
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq runScriptJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+
+	script, err := storage.GetScript(GetParam("name", r))
+	if err != nil {
+		return nil, scriptError(err)
+	}
+
+	audit.AddKeys(r, bodyReq.Keys...)
+	audit.AddDetail(r, "Args", bodyReq.Args)
+	started := time.Now()
+	reply, err := db.RunScript(serverName, dbNum, script.Body, bodyReq.Keys, bodyReq.Args)
+	duration := time.Since(started)
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return executeCommandResponse{Reply: reply, DurationMicroseconds: duration.Nanoseconds() / 1000}, nil
+}
+
+func scriptError(err error) error {
+	if _, ok := err.(storage.ScriptNotFoundError); ok {
+		return responds.NewNotFoundError(err.Error())
+	}
+	return err
+}
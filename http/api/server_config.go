@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sad0vnikov/radish/audit"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+//GetServerConfig returns server configuration parameters matching 'pattern' param
+func GetServerConfig(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := GetParam("pattern", r)
+	if len(pattern) == 0 {
+		pattern = "*"
+	}
+
+	params, err := db.GetServerConfig(GetParam("server", r), pattern)
+	if err != nil {
+		return nil, configError(err)
+	}
+
+	return params, nil
+}
+
+type setConfigParamJSONRequest struct {
+	Value   *string
+	Rewrite bool
+}
+
+//SetServerConfigParam changes a server configuration parameter
+func SetServerConfigParam(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "param"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var bodyReq setConfigParamJSONRequest
+	err = decoder.Decode(&bodyReq)
+	if err != nil {
+		logger.Errorf("error while parsing JSON: %v", err)
+		return nil, responds.NewBadRequestError("got invalid JSON")
+	}
+	if bodyReq.Value == nil {
+		return nil, responds.NewBadRequestError("JSON `Value` param is missing")
+	}
+
+	change, err := db.SetServerConfigParam(GetParam("server", r), GetParam("param", r), *bodyReq.Value, bodyReq.Rewrite)
+	newValue := *bodyReq.Value
+	if db.IsSecretConfigParam(change.Name) {
+		newValue = db.HiddenConfigValue
+	}
+	audit.AddDetail(r, "OldValue", change.OldValue)
+	audit.AddDetail(r, "NewValue", newValue)
+	if err != nil {
+		return nil, configError(err)
+	}
+
+	return change, nil
+}
+
+//RewriteServerConfig rewrites server configuration file with the current configuration
+func RewriteServerConfig(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.RewriteServerConfig(GetParam("server", r))
+	if err != nil {
+		return nil, configError(err)
+	}
+
+	return "", nil
+}
+
+func configError(err error) error {
+	switch err.(type) {
+	case db.CommandDisabledError:
+		return responds.NewForbiddenError(err.Error())
+	case db.ConfigValidationError:
+		return responds.NewBadRequestError(err.Error())
+	}
+	return err
+}
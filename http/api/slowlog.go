@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+const defaultSlowlogEntriesCount = 128
+
+type slowlogResponse struct {
+	Entries []db.SlowlogEntry
+	Groups  []db.SlowlogGroup
+	Length  int64
+}
+
+//GetSlowlog returns parsed slowlog entries for a server
+//entries can be grouped by command name or by key prefix with 'groupBy' param
+func GetSlowlog(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+
+	count := defaultSlowlogEntriesCount
+	if countParam := GetParam("count", r); len(countParam) > 0 {
+		count, err = strconv.Atoi(countParam)
+		if err != nil || count <= 0 {
+			return nil, responds.NewBadRequestError("'count' should be a positive number")
+		}
+	}
+
+	entries, err := db.GetSlowlog(serverName, count)
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := db.GetSlowlogLen(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	response := slowlogResponse{Entries: entries, Length: length}
+
+	if groupBy := GetParam("groupBy", r); len(groupBy) > 0 {
+		delimiter := GetParam("delimiter", r)
+		if len(delimiter) == 0 {
+			delimiter = ":"
+		}
+		response.Groups, err = db.GroupSlowlogEntries(entries, groupBy, delimiter)
+		if err != nil {
+			return nil, responds.NewBadRequestError(err.Error())
+		}
+	}
+
+	return response, nil
+}
+
+//GetSlowlogLen returns server slowlog length
+func GetSlowlogLen(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetSlowlogLen(GetParam("server", r))
+}
+
+//ResetSlowlog clears server slowlog
+func ResetSlowlog(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.ResetSlowlog(GetParam("server", r))
+	if err != nil {
+		return nil, err
+	}
+
+	return "", nil
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+//GetSnapshots returns the latest key snapshots taken before changes made with Radish
+//snapshots can be filtered with 'db' and 'key' params, snapshots of keys the user can't read are not returned
+func GetSnapshots(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+
+	var dbNum *uint8
+	if len(GetParam("db", r)) > 0 {
+		n, err := GetParamUint8("db", r)
+		if err != nil {
+			return nil, responds.NewBadRequestError("'db' should be a database number")
+		}
+		dbNum = &n
+	}
+
+	result := []db.Snapshot{}
+	for _, s := range db.GetSnapshots(serverName, dbNum, GetParam("key", r)) {
+		p := auth.Permission{Server: serverName, DB: int(s.DB), Key: s.Key, Level: redis.AccessRead}
+		if auth.Authorize(r, p) == nil {
+			result = append(result, s)
+		}
+	}
+
+	return result, nil
+}
+
+//RestoreSnapshot puts a key back to the state saved in a snapshot given in 'id' param
+func RestoreSnapshot(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "key", "id"}, r)
+	if err != nil {
+		return nil, err
+	}
+	var dbNum uint8
+	dbNum, err = GetParamUint8("db", r)
+
+	id, err := strconv.ParseUint(GetParam("id", r), 10, 64)
+	if err != nil {
+		return nil, responds.NewBadRequestError("'id' should be a snapshot ID")
+	}
+
+	snapshot, err := db.RestoreSnapshot(GetParam("server", r), dbNum, GetParam("key", r), id)
+	switch err.(type) {
+	case db.SnapshotNotFoundError:
+		return nil, responds.NewNotFoundError(err.Error())
+	case db.SnapshotNotRestorableError:
+		return nil, responds.NewConflictError(err.Error())
+	case db.ServerModeError:
+		return nil, responds.NewForbiddenError(err.Error())
+	}
+	if err != nil {
+		return nil, commandPolicyError(err)
+	}
+
+	return snapshot, nil
+}
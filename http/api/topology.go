@@ -0,0 +1,14 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+//GetTopology returns a replication graph of configured servers the user can read with lag of every replica
+func GetTopology(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return db.GetTopology().FilterServers(func(server string) bool {
+		return canReadServer(r, server)
+	}), nil
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+//GetTrash returns keys deleted from a server and kept in its trash, keys the user can't read are not returned
+func GetTrash(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	serverName := GetParam("server", r)
+
+	keys, err := db.GetTrash(serverName)
+	if err != nil {
+		return nil, trashError(err)
+	}
+
+	result := []db.TrashedKey{}
+	for _, k := range keys {
+		if auth.Authorize(r, auth.Permission{Server: serverName, DB: int(k.DB), Key: k.Key, Level: redis.AccessRead}) == nil {
+			result = append(result, k)
+		}
+	}
+	return result, nil
+}
+
+//RestoreTrashedKey puts a key given in 'id' param back to its database
+//an existing key with the same name is replaced only if 'replace' param is true
+func RestoreTrashedKey(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "id"}, r)
+	if err != nil {
+		return nil, err
+	}
+	err = authorizeTrashedKey(r)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := db.RestoreTrashedKey(GetParam("server", r), GetParam("id", r), GetParam("replace", r) == "true")
+	if err != nil {
+		return nil, trashError(err)
+	}
+	return k, nil
+}
+
+//PurgeTrashedKey removes a key given in 'id' param from the trash for good
+func PurgeTrashedKey(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server", "id"}, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorizeTrashedKey(r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.PurgeTrash(GetParam("server", r), GetParam("id", r))
+	if err != nil {
+		return nil, trashError(err)
+	}
+	return "", nil
+}
+
+//PurgeTrash removes all keys from the server trash for good
+func PurgeTrash(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	err := CheckRequiredParams([]string{"server"}, r)
+	if err != nil {
+		return nil, err
+	}
+	err = CheckConfirmed(r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.PurgeTrash(GetParam("server", r), "")
+	if err != nil {
+		return nil, trashError(err)
+	}
+	return "", nil
+}
+
+//authorizeTrashedKey checks the request user can change a key given in 'id' param in the database it was deleted from
+func authorizeTrashedKey(r *http.Request) error {
+	serverName := GetParam("server", r)
+	k, err := db.GetTrashedKey(serverName, GetParam("id", r))
+	if err != nil {
+		return trashError(err)
+	}
+
+	err = auth.Authorize(r, auth.Permission{Server: serverName, DB: int(k.DB), Key: k.Key, Level: redis.AccessWrite})
+	if err != nil {
+		return responds.NewForbiddenError(err.Error())
+	}
+	return nil
+}
+
+func trashError(err error) error {
+	switch err.(type) {
+	case db.TrashNotConfiguredError, db.TrashedKeyNotFoundError:
+		return responds.NewNotFoundError(err.Error())
+	case db.KeyExistsError:
+		return responds.NewConflictError(err.Error())
+	case db.ServerModeError:
+		return responds.NewForbiddenError(err.Error())
+	}
+	return commandPolicyError(err)
+}
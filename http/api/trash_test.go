@@ -0,0 +1,77 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/config"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis"
+	"github.com/sad0vnikov/radish/redis/db"
+	"github.com/sad0vnikov/radish/storage"
+)
+
+func TestRefusingTrashedKeyOfForbiddenDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radish-test-trash-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config.StubConfigLoader{DataDir: dir}.Load()
+	defer config.StubConfigLoader{}.Load()
+	servers := config.Get().Servers
+	srv := servers["server1"]
+	srv.Trash = &redis.TrashConfig{Mode: redis.TrashLocal, RetentionHours: 1}
+	servers["server1"] = srv
+
+	trashed := map[string]interface{}{
+		"abc": map[string]interface{}{"ID": "abc", "Key": "user:1", "DB": 0, "Type": "string", "PTTL": -1, "Expires": time.Now().Add(time.Hour).Unix()},
+	}
+	err = storage.JSONFile{Path: filepath.Join(dir, "trash", "server1.json")}.Write(trashed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = auth.Init(config.AuthConfig{
+		//SHA-256 of 'ci-token'
+		APITokens: []config.APIToken{{Name: "ci", TokenSHA256: "948b8c2427cd29047839b8e4a27a08763f8befbafa86be5cce8e46217d75e58a"}},
+		Roles: []config.Role{
+			{Name: "db3-writer", Rules: []config.PermissionRule{{Servers: []string{"server1"}, Databases: "3", Verbs: []string{"read", "write"}}}},
+		},
+		RoleBindings: []config.RoleBinding{{Role: "db3-writer", Users: []string{"token:ci"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auth.Init(config.AuthConfig{})
+
+	handlers := map[string]func(w http.ResponseWriter, r *http.Request) (interface{}, error){
+		"restore": RestoreTrashedKey,
+		"purge":   PurgeTrashedKey,
+	}
+	for name, h := range handlers {
+		r := httptest.NewRequest("POST", "/api/v1/servers/server1/trash/abc?db=3", nil)
+		r.Header.Set("Authorization", "Bearer ci-token")
+		r, err = auth.Authenticate(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = mux.SetURLVars(r, map[string]string{"server": "server1", "id": "abc"})
+
+		_, err = h(httptest.NewRecorder(), r)
+		if _, ok := err.(*responds.APIForbiddenError); !ok {
+			t.Errorf("%v: got error %v, expected a user of db 3 to be refused a key deleted from db 0", name, err)
+		}
+	}
+
+	if _, err := db.GetTrashedKey("server1", "abc"); err != nil {
+		t.Errorf("expected the key to be kept in the trash, got %v", err)
+	}
+}
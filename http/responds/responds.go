@@ -2,7 +2,6 @@ package responds
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"github.com/sad0vnikov/radish/logger"
@@ -50,26 +49,124 @@ func NewConflictError(msg string) error {
 	return &APIConflictError{msg}
 }
 
+//APIForbiddenError is a 403 HTTP error
+type APIForbiddenError struct {
+	msg string
+}
+
+func (err APIForbiddenError) Error() string {
+	return err.msg
+}
+
+//NewForbiddenError returns a new APIForbiddenError
+func NewForbiddenError(msg string) error {
+	return &APIForbiddenError{msg}
+}
+
+//APIUnauthorizedError is a 401 HTTP error
+type APIUnauthorizedError struct {
+	msg string
+}
+
+func (err APIUnauthorizedError) Error() string {
+	return err.msg
+}
+
+//NewUnauthorizedError returns a new APIUnauthorizedError
+func NewUnauthorizedError(msg string) error {
+	return &APIUnauthorizedError{msg}
+}
+
+//APIPreconditionFailedError is a 412 HTTP error
+type APIPreconditionFailedError struct {
+	msg string
+}
+
+func (err APIPreconditionFailedError) Error() string {
+	return err.msg
+}
+
+//NewPreconditionFailedError returns a new APIPreconditionFailedError
+func NewPreconditionFailedError(msg string) error {
+	return &APIPreconditionFailedError{msg}
+}
+
+//RequestIDHeader is a response header with a request ID, the ID is included to error responses too
+const RequestIDHeader = "X-Request-ID"
+
+//Error codes of error responses
+const (
+	CodeInternalError         = "internal_error"
+	CodeBadRequest            = "bad_request"
+	CodeNotFound              = "not_found"
+	CodeConflict              = "conflict"
+	CodeForbidden             = "forbidden"
+	CodeUnauthorized          = "unauthorized"
+	CodePreconditionFailed    = "precondition_failed"
+	CodeWrongType             = "wrong_type"
+	CodeRedisError            = "redis_error"
+	CodeRedisAuthFailed       = "redis_auth_failed"
+	CodeRedisPermissionDenied = "redis_permission_denied"
+	CodeRedisReadOnly         = "redis_read_only"
+	CodeRedisOutOfMemory      = "redis_out_of_memory"
+	CodeRedisUnavailable      = "redis_unavailable"
+	CodeRedisTimeout          = "redis_timeout"
+)
+
+//ErrorResponse is a JSON body of error responses
+//Code is a machine-readable error code, Details are optional error-specific data
+type ErrorResponse struct {
+	Code      string
+	Message   string
+	Details   interface{} `json:",omitempty"`
+	RequestID string      `json:",omitempty"`
+}
+
+//RespondError responds with an HTTP status and a JSON error body, the request ID is taken from RequestIDHeader response header
+func RespondError(w http.ResponseWriter, status int, code, message string, details interface{}) {
+	body, err := json.Marshal(ErrorResponse{Code: code, Message: message, Details: details, RequestID: w.Header().Get(RequestIDHeader)})
+	if err != nil {
+		body, _ = json.Marshal(ErrorResponse{Code: code, Message: message, RequestID: w.Header().Get(RequestIDHeader)})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
 //RespondInternalError responds with 500 Internal Error HTTP status
-func RespondInternalError(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusInternalServerError)
+func RespondInternalError(w http.ResponseWriter, message string) {
+	RespondError(w, http.StatusInternalServerError, CodeInternalError, message, nil)
 }
 
 //RespondBadRequest responds with 400 Bad Request HTTP status
 func RespondBadRequest(w http.ResponseWriter, message string) {
-	w.WriteHeader(http.StatusBadRequest)
-	fmt.Fprintf(w, message)
+	RespondError(w, http.StatusBadRequest, CodeBadRequest, message, nil)
 }
 
 //RespondNotFound responds with 404 Not Found HTTP status
-func RespondNotFound(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusNotFound)
+func RespondNotFound(w http.ResponseWriter, message string) {
+	RespondError(w, http.StatusNotFound, CodeNotFound, message, nil)
 }
 
 //RespondConflictError responds with 309 HTTP error
 func RespondConflictError(w http.ResponseWriter, message string) {
-	w.WriteHeader(http.StatusConflict)
-	fmt.Fprintf(w, message)
+	RespondError(w, http.StatusConflict, CodeConflict, message, nil)
+}
+
+//RespondForbidden responds with 403 Forbidden HTTP status
+func RespondForbidden(w http.ResponseWriter, message string) {
+	RespondError(w, http.StatusForbidden, CodeForbidden, message, nil)
+}
+
+//RespondUnauthorized responds with 401 Unauthorized HTTP status
+func RespondUnauthorized(w http.ResponseWriter, message string) {
+	RespondError(w, http.StatusUnauthorized, CodeUnauthorized, message, nil)
+}
+
+//RespondPreconditionFailed responds with 412 Precondition Failed HTTP status
+func RespondPreconditionFailed(w http.ResponseWriter, message string) {
+	RespondError(w, http.StatusPreconditionFailed, CodePreconditionFailed, message, nil)
 }
 
 //RespondJSON writes JSON to http output
@@ -77,7 +174,7 @@ func RespondJSON(w http.ResponseWriter, response interface{}) {
 	responseMarshal, err := json.Marshal(response)
 	if err != nil {
 		logger.Error(err)
-		RespondInternalError(w)
+		RespondInternalError(w, "can't encode response")
 		return
 	}
 
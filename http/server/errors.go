@@ -0,0 +1,123 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"syscall"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+//setRequestID puts a request ID to the response headers, an ID given by a client in the same request header is reused
+func setRequestID(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(responds.RequestIDHeader)
+	if !validRequestID.MatchString(id) {
+		b := make([]byte, 8)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+	w.Header().Set(responds.RequestIDHeader, id)
+}
+
+//respondError responds with a JSON error body, API errors returned by handlers are responded with their HTTP statuses,
+//other errors are classified by errorStatus
+func respondError(w http.ResponseWriter, err error) {
+	if nerr, ok := err.(*responds.APINotFoundError); ok {
+		responds.RespondNotFound(w, nerr.Error())
+		return
+	}
+	if brerr, ok := err.(*responds.APIBadRequestError); ok {
+		responds.RespondBadRequest(w, brerr.Error())
+		return
+	}
+	if cerr, ok := err.(*responds.APIConflictError); ok {
+		responds.RespondConflictError(w, cerr.Error())
+		return
+	}
+	if ferr, ok := err.(*responds.APIForbiddenError); ok {
+		responds.RespondForbidden(w, ferr.Error())
+		return
+	}
+	if uerr, ok := err.(*responds.APIUnauthorizedError); ok {
+		responds.RespondUnauthorized(w, uerr.Error())
+		return
+	}
+	if perr, ok := err.(*responds.APIPreconditionFailedError); ok {
+		responds.RespondPreconditionFailed(w, perr.Error())
+		return
+	}
+
+	status, code := errorStatus(err)
+	if code == responds.CodeInternalError {
+		//unclassified errors may contain server addresses or file paths, so they are only logged
+		requestID := w.Header().Get(responds.RequestIDHeader)
+		logger.Info("request " + requestID + " failed: " + err.Error())
+		responds.RespondInternalError(w, "internal server error, see Radish log for request "+requestID)
+		return
+	}
+	responds.RespondError(w, status, code, err.Error(), errorDetails(err))
+}
+
+//errorStatus returns an HTTP status and an error code for an error returned by Redis or by a connection to it,
+//Redis errors are classified by their prefixes, e.g. WRONGTYPE or READONLY
+func errorStatus(err error) (int, string) {
+	var rerr redigo.Error
+	if errors.As(err, &rerr) {
+		switch strings.SplitN(string(rerr), " ", 2)[0] {
+		case "WRONGTYPE":
+			return http.StatusConflict, responds.CodeWrongType
+		case "NOAUTH", "WRONGPASS":
+			return http.StatusBadGateway, responds.CodeRedisAuthFailed
+		case "NOPERM":
+			return http.StatusForbidden, responds.CodeRedisPermissionDenied
+		case "READONLY":
+			return http.StatusForbidden, responds.CodeRedisReadOnly
+		case "OOM":
+			return http.StatusInsufficientStorage, responds.CodeRedisOutOfMemory
+		case "LOADING", "BUSY", "MASTERDOWN":
+			return http.StatusServiceUnavailable, responds.CodeRedisUnavailable
+		}
+		return http.StatusInternalServerError, responds.CodeRedisError
+	}
+
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		return http.StatusGatewayTimeout, responds.CodeRedisTimeout
+	}
+	var operr *net.OpError
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.As(err, &operr) {
+		return http.StatusBadGateway, responds.CodeRedisUnavailable
+	}
+
+	return http.StatusInternalServerError, responds.CodeInternalError
+}
+
+//errorDetails returns a Redis error message and a failed command of a transaction
+func errorDetails(err error) interface{} {
+	details := make(map[string]interface{})
+
+	var rerr redigo.Error
+	if errors.As(err, &rerr) {
+		details["RedisError"] = string(rerr)
+	}
+	var cerr db.TransactionCommandError
+	if errors.As(err, &cerr) {
+		details["Command"] = cerr.Command
+		details["Index"] = cerr.Index
+	}
+
+	if len(details) == 0 {
+		return nil
+	}
+	return details
+}
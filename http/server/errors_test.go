@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyingErrors(t *testing.T) {
+	connRefused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{redigo.Error("WRONGTYPE Operation against a key holding the wrong kind of value"), http.StatusConflict, responds.CodeWrongType},
+		{redigo.Error("NOAUTH Authentication required."), http.StatusBadGateway, responds.CodeRedisAuthFailed},
+		{redigo.Error("READONLY You can't write against a read only replica."), http.StatusForbidden, responds.CodeRedisReadOnly},
+		{redigo.Error("OOM command not allowed when used memory > 'maxmemory'."), http.StatusInsufficientStorage, responds.CodeRedisOutOfMemory},
+		{redigo.Error("ERR unknown command"), http.StatusInternalServerError, responds.CodeRedisError},
+		{db.TransactionCommandError{Command: "SADD", Err: redigo.Error("WRONGTYPE Operation against a key holding the wrong kind of value")}, http.StatusConflict, responds.CodeWrongType},
+		{connRefused, http.StatusBadGateway, responds.CodeRedisUnavailable},
+		{&net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}, http.StatusGatewayTimeout, responds.CodeRedisTimeout},
+		{errors.New("100% broken"), http.StatusInternalServerError, responds.CodeInternalError},
+	}
+
+	for _, c := range cases {
+		status, code := errorStatus(c.err)
+		if status != c.status || code != c.code {
+			t.Errorf("got %v %v for error %v, expected %v %v", status, code, c.err, c.status, c.code)
+		}
+	}
+}
+
+func TestRespondingJSONErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PUT", "/api/v1/servers/server1/keys/sets/tags/values/a", nil)
+	r.Header.Set(responds.RequestIDHeader, "req-1")
+	setRequestID(w, r)
+
+	respondError(w, db.TransactionCommandError{Command: "SADD", Index: 1, Err: redigo.Error("WRONGTYPE Operation against a key holding the wrong kind of value")})
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("got status %v, expected %v", w.Code, http.StatusConflict)
+	}
+	var body map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"Code":    responds.CodeWrongType,
+		"Message": "command SADD (#2 in the transaction) failed: WRONGTYPE Operation against a key holding the wrong kind of value",
+		"Details": map[string]interface{}{
+			"RedisError": "WRONGTYPE Operation against a key holding the wrong kind of value",
+			"Command":    "SADD",
+			"Index":      float64(1),
+		},
+		"RequestID": "req-1",
+	}
+	if !reflect.DeepEqual(body, expected) {
+		t.Errorf("got error body %v, expected %v", body, expected)
+	}
+
+	w = httptest.NewRecorder()
+	r.Header.Set(responds.RequestIDHeader, "req-2")
+	setRequestID(w, r)
+	respondError(w, errors.New("open /var/lib/radish/audit.log: permission denied"))
+	var internal responds.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &internal)
+	if w.Code != http.StatusInternalServerError || internal.Code != responds.CodeInternalError || internal.RequestID != "req-2" || strings.Contains(internal.Message, "audit.log") {
+		t.Errorf("got %v response %+v, expected internal error without error details", w.Code, internal)
+	}
+
+	w = httptest.NewRecorder()
+	setRequestID(w, httptest.NewRequest("GET", "/", nil))
+	respondError(w, responds.NewNotFoundError("key user:1 doesn't exist"))
+	var notFound responds.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &notFound)
+	if w.Code != http.StatusNotFound || notFound.Code != responds.CodeNotFound || notFound.Message != "key user:1 doesn't exist" || len(notFound.RequestID) == 0 {
+		t.Errorf("got %v response %+v, expected not found error with a generated request ID", w.Code, notFound)
+	}
+}
@@ -1,15 +1,20 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"strings"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/sad0vnikov/radish/audit"
+	"github.com/sad0vnikov/radish/auth"
 	"github.com/sad0vnikov/radish/config"
 	"github.com/sad0vnikov/radish/http/responds"
 	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis"
 )
 
 //HTTPServer server configuration
@@ -19,6 +24,8 @@ type HTTPServer struct {
 
 type apiHandler func(w http.ResponseWriter, r *http.Request) (interface{}, error)
 
+type streamHandler func(w http.ResponseWriter, r *http.Request) error
+
 var router = mux.NewRouter()
 
 // Init starts http server
@@ -33,31 +40,83 @@ func (server HTTPServer) Init() {
 //ServeStatic turns on serving Radish panel static files
 func (server HTTPServer) ServeStatic() {
 	fs := http.FileServer(http.Dir("html/dist"))
-	var URLPrefix = getURLPrefix()
+	var URLPrefix = GetURLPrefix()
 	router.PathPrefix(URLPrefix).Handler(http.StripPrefix(URLPrefix, fs))
 }
 
 //AddHandler adds a http handler
+//GET handlers are allowed on servers in any mode, handlers of other methods change data and are refused on read-only servers,
+//calls of handlers changing data are recorded to the audit log
 func (server HTTPServer) AddHandler(method, path string, h apiHandler) {
-	var URLPrefix = getURLPrefix()
+	server.addHandler(method, path, methodAccessLevel(method), false, authorize, h)
+}
+
+//AddKeysHandler adds a http handler changing keys given in the request body rather than in the URL
+//the request user is required to have the access level for some keys of the database,
+//so the handler has to authorize every key by itself
+func (server HTTPServer) AddKeysHandler(method, path string, h apiHandler) {
+	server.addHandler(method, path, methodAccessLevel(method), false, authorizeAnyKey, h)
+}
+
+//AddCommandsHandler adds a http handler running arbitrary commands, e.g. a console or Lua scripts
+//commands like MOVE, COPY or SELECT can reach any database, so the request user is required to have the access level
+//for all databases of the server
+func (server HTTPServer) AddCommandsHandler(method, path string, h apiHandler) {
+	server.addHandler(method, path, methodAccessLevel(method), false, authorizeServer, h)
+}
+
+//AddServerHandler adds a http handler returning data about all databases of a server, e.g. ACL users
+//the request user is required to have the access level for all databases of the server
+func (server HTTPServer) AddServerHandler(method, path string, h apiHandler) {
+	server.addHandler(method, path, methodAccessLevel(method), false, authorizeServer, h)
+}
+
+//AddAdminHandler adds a http handler performing administrative operations, it's refused on read-only and protected servers
+func (server HTTPServer) AddAdminHandler(method, path string, h apiHandler) {
+	server.addHandler(method, path, redis.AccessAdmin, false, authorize, h)
+}
+
+//AddLibraryHandler adds a http handler changing Radish data shared by all servers, e.g. the saved scripts library,
+//the request user is required to be an admin of all servers
+func (server HTTPServer) AddLibraryHandler(method, path string, h apiHandler) {
+	server.addHandler(method, path, redis.AccessAdmin, false, authorizeAllServers, h)
+}
+
+//AddPublicHandler adds a http handler which doesn't require authentication, e.g. a login handler
+func (server HTTPServer) AddPublicHandler(method, path string, h apiHandler) {
+	server.addHandler(method, path, methodAccessLevel(method), true, nil, h)
+}
+
+func (server HTTPServer) addHandler(method, path string, level redis.AccessLevel, public bool, authz func(r *http.Request, level redis.AccessLevel) error, h apiHandler) {
+	var URLPrefix = GetURLPrefix()
 	router.HandleFunc(
 		URLPrefix+"api/"+path,
 		func(w http.ResponseWriter, r *http.Request) {
-			resp, err := h(w, r)
-			if _, ok := err.(*responds.APINotFoundError); ok {
-				responds.RespondNotFound(w)
+			setRequestID(w, r)
+			r, err := authenticate(r)
+			if err != nil && !public {
+				respondError(w, err)
 				return
 			}
-			if brerr, ok := err.(*responds.APIBadRequestError); ok {
-				responds.RespondBadRequest(w, brerr.Error())
-				return
+
+			err = checkServerMode(r, level)
+			if err == nil && authz != nil {
+				err = authz(r, level)
 			}
-			if cerr, ok := err.(*responds.APIConflictError); ok {
-				responds.RespondConflictError(w, cerr.Error())
+			if err != nil {
+				respondError(w, err)
 				return
 			}
+
+			var recording *audit.Recording
+			if methodAccessLevel(method) != redis.AccessRead && !public {
+				recording = audit.Start(r, method+" "+path)
+				r = recording.WithRequest(r)
+			}
+			resp, err := h(w, r)
+			recording.Finish(err)
 			if err != nil {
-				responds.RespondInternalError(w)
+				respondError(w, err)
 				return
 			}
 
@@ -66,12 +125,160 @@ func (server HTTPServer) AddHandler(method, path string, h apiHandler) {
 		Methods(method)
 }
 
+//AddStreamHandler adds a http handler writing the response by itself, e.g. a stream of server-sent events
+//an error is responded only if the handler returns it before writing anything
+func (server HTTPServer) AddStreamHandler(method, path string, h streamHandler) {
+	server.addStreamHandler(method, path, false, h)
+}
+
+//AddPublicStreamHandler adds a http handler writing the response by itself which doesn't require authentication, e.g. a login redirect
+func (server HTTPServer) AddPublicStreamHandler(method, path string, h streamHandler) {
+	server.addStreamHandler(method, path, true, h)
+}
+
+func (server HTTPServer) addStreamHandler(method, path string, public bool, h streamHandler) {
+	var URLPrefix = GetURLPrefix()
+	router.HandleFunc(
+		URLPrefix+"api/"+path,
+		func(w http.ResponseWriter, r *http.Request) {
+			setRequestID(w, r)
+			r, err := authenticate(r)
+			if public {
+				err = nil
+			}
+			if err == nil {
+				err = checkServerMode(r, methodAccessLevel(method))
+			}
+			if err == nil && !public {
+				err = authorize(r, methodAccessLevel(method))
+			}
+			if err == nil {
+				err = h(w, r)
+			}
+			if err != nil {
+				respondError(w, err)
+			}
+		}).
+		Methods(method)
+}
+
+//authenticate checks request credentials, the authenticated user is stored in the returned request context
+func authenticate(r *http.Request) (*http.Request, error) {
+	r, err := auth.Authenticate(r)
+	switch err {
+	case auth.ErrUnauthenticated:
+		return r, responds.NewUnauthorizedError(err.Error())
+	case auth.ErrCSRFTokenMismatch:
+		return r, responds.NewForbiddenError(err.Error())
+	}
+	return r, err
+}
+
+func methodAccessLevel(method string) redis.AccessLevel {
+	if method == "GET" || method == "HEAD" {
+		return redis.AccessRead
+	}
+	return redis.AccessWrite
+}
+
+//checkServerMode returns a forbidden error if the mode of a server given in 'server' URL param doesn't permit the access level
+func checkServerMode(r *http.Request, level redis.AccessLevel) error {
+	serverName := mux.Vars(r)["server"]
+	server, prs := config.Get().Servers[serverName]
+	if !prs || server.Allows(level) {
+		return nil
+	}
+
+	return responds.NewForbiddenError(fmt.Sprintf("server %v is in %v mode, the operation is not allowed", serverName, server.Mode))
+}
+
+//authorize checks request user roles grant the access level on a server given in 'server' URL param
+//requests with a 'key' URL param are checked for the key in a database given in 'db' param or in db 0,
+//reading requests without a key are allowed to users who can read any key, their results should be filtered by handlers,
+//other requests without a key require a permission for all keys of the database or of all databases if 'db' is not given
+func authorize(r *http.Request, level redis.AccessLevel) error {
+	p, ok := requestPermission(r, level)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	if level == redis.AccessRead && p.Key == auth.AllKeys {
+		err = auth.AuthorizeAny(r, p)
+	} else {
+		err = auth.Authorize(r, p)
+	}
+	if err != nil {
+		return responds.NewForbiddenError(err.Error())
+	}
+	return nil
+}
+
+//authorizeAnyKey checks request user roles grant the access level for some keys of a database given in 'db' param
+//or of any database if it's not given on a server given in 'server' URL param
+func authorizeAnyKey(r *http.Request, level redis.AccessLevel) error {
+	p, ok := requestPermission(r, level)
+	if !ok {
+		return nil
+	}
+
+	err := auth.AuthorizeAny(r, p)
+	if err != nil {
+		return responds.NewForbiddenError(err.Error())
+	}
+	return nil
+}
+
+//authorizeServer checks request user roles grant the access level for all keys of all databases of a server given in 'server' URL param
+func authorizeServer(r *http.Request, level redis.AccessLevel) error {
+	p, ok := requestPermission(r, level)
+	if !ok {
+		return nil
+	}
+
+	p.DB = auth.AllDatabases
+	p.Key = auth.AllKeys
+	err := auth.Authorize(r, p)
+	if err != nil {
+		return responds.NewForbiddenError(err.Error())
+	}
+	return nil
+}
+
+//authorizeAllServers checks request user roles grant the access level for all keys of all servers
+func authorizeAllServers(r *http.Request, level redis.AccessLevel) error {
+	err := auth.Authorize(r, auth.Permission{Server: auth.AllServers, DB: auth.AllDatabases, Key: auth.AllKeys, Level: level})
+	if err != nil {
+		return responds.NewForbiddenError(err.Error())
+	}
+	return nil
+}
+
+//requestPermission returns a permission required for a request to a server given in 'server' URL param, false is returned if there is no server
+func requestPermission(r *http.Request, level redis.AccessLevel) (auth.Permission, bool) {
+	vars := mux.Vars(r)
+	serverName := vars["server"]
+	if len(serverName) == 0 {
+		return auth.Permission{}, false
+	}
+
+	p := auth.Permission{Server: serverName, DB: auth.AllDatabases, Key: vars["key"], Level: level}
+	dbNum, err := strconv.ParseUint(r.URL.Query().Get("db"), 10, 8)
+	if err == nil {
+		p.DB = int(dbNum)
+	} else if p.Key != auth.AllKeys {
+		p.DB = 0
+	}
+	return p, true
+}
+
 //GetURLParams returns request params from given HTTP request
 func GetURLParams(request *http.Request) map[string]string {
 	return mux.Vars(request)
 }
 
-func getURLPrefix() string {
+//GetURLPrefix returns a prefix of Radish URLs ending with a slash
+func GetURLPrefix() string {
 	var URLPrefix = config.Get().URLPrefix
 
 	if !strings.HasSuffix(URLPrefix, "/") {
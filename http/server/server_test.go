@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sad0vnikov/radish/auth"
+	"github.com/sad0vnikov/radish/config"
+	"github.com/sad0vnikov/radish/http/responds"
+	"github.com/sad0vnikov/radish/redis"
+)
+
+func TestEnforcingServerModes(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	defer config.StubConfigLoader{}.Load()
+	servers := config.Get().Servers
+	readOnly := servers["server2"]
+	readOnly.Mode = redis.ModeReadOnly
+	servers["server2"] = readOnly
+	protected := servers["server3"]
+	protected.Mode = redis.ModeProtected
+	servers["server3"] = protected
+
+	called := 0
+	h := func(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+		called++
+		return "ok", nil
+	}
+	s := HTTPServer{}
+	for _, method := range []string{"GET", "POST", "PUT", "DELETE"} {
+		s.AddHandler(method, "test-modes/servers/{server}/keys/{key}", h)
+	}
+	s.AddAdminHandler("POST", "test-modes/servers/{server}/flush", h)
+
+	cases := []struct {
+		method  string
+		path    string
+		allowed bool
+	}{
+		{"GET", "servers/server2/keys/user:1", true},
+		{"POST", "servers/server2/keys/user:1", false},
+		{"PUT", "servers/server2/keys/user:1", false},
+		{"DELETE", "servers/server2/keys/user:1", false},
+		{"POST", "servers/server3/keys/user:1", true},
+		{"DELETE", "servers/server3/keys/user:1", true},
+		{"POST", "servers/server3/flush", false},
+		{"POST", "servers/server2/flush", false},
+		{"POST", "servers/server1/flush", true},
+	}
+
+	for _, c := range cases {
+		called = 0
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(c.method, GetURLPrefix()+"api/test-modes/"+c.path, nil))
+
+		if c.allowed {
+			if w.Code != http.StatusOK || called != 1 {
+				t.Errorf("%v %v: got status %v, expected the handler to be called", c.method, c.path, w.Code)
+			}
+			continue
+		}
+		var body responds.ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if w.Code != http.StatusForbidden || body.Code != responds.CodeForbidden || len(body.Message) == 0 || called != 0 {
+			t.Errorf("%v %v: got %v response %+v, expected a forbidden error without calling the handler", c.method, c.path, w.Code, body)
+		}
+	}
+}
+
+func TestAuthorizingCommandsForAllDatabases(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	err := auth.Init(config.AuthConfig{
+		//SHA-256 of 'ci-token'
+		APITokens: []config.APIToken{{Name: "ci", TokenSHA256: "948b8c2427cd29047839b8e4a27a08763f8befbafa86be5cce8e46217d75e58a"}},
+		Roles: []config.Role{
+			{Name: "db0-writer", Rules: []config.PermissionRule{{Servers: []string{"server1"}, Databases: "0", Verbs: []string{"read", "write"}}}},
+		},
+		RoleBindings: []config.RoleBinding{{Role: "db0-writer", Users: []string{"token:ci"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auth.Init(config.AuthConfig{})
+
+	called := 0
+	h := func(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+		called++
+		return "ok", nil
+	}
+	s := HTTPServer{}
+	s.AddHandler("POST", "test-commands/servers/{server}/keys/{key}", h)
+	s.AddCommandsHandler("POST", "test-commands/servers/{server}/console", h)
+	s.AddServerHandler("GET", "test-commands/servers/{server}/acl/users", h)
+
+	request := func(method, path string) int {
+		r := httptest.NewRequest(method, GetURLPrefix()+"api/test-commands/servers/server1/"+path, nil)
+		r.Header.Set("Authorization", "Bearer ci-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := request("POST", "keys/user:1?db=0"); code != http.StatusOK {
+		t.Errorf("got status %v, expected a user to change a key of a permitted database", code)
+	}
+	if code := request("POST", "console?db=0"); code != http.StatusForbidden || called != 1 {
+		t.Errorf("got status %v, expected a user restricted to a database to be refused running commands", code)
+	}
+	if code := request("GET", "acl/users?db=0"); code != http.StatusForbidden || called != 1 {
+		t.Errorf("got status %v, expected a user restricted to a database to be refused reading ACL users", code)
+	}
+}
+
+func TestAuthorizingLibraryChangesForAllServers(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	defer auth.Init(config.AuthConfig{})
+
+	called := 0
+	h := func(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+		called++
+		return "ok", nil
+	}
+	s := HTTPServer{}
+	s.AddLibraryHandler("PUT", "test-library/scripts/{name}", h)
+
+	request := func(role string) int {
+		err := auth.Init(config.AuthConfig{
+			//SHA-256 of 'ci-token'
+			APITokens: []config.APIToken{{Name: "ci", TokenSHA256: "948b8c2427cd29047839b8e4a27a08763f8befbafa86be5cce8e46217d75e58a"}},
+			Roles: []config.Role{
+				{Name: "server1-admin", Rules: []config.PermissionRule{{Servers: []string{"server1"}, Verbs: []string{"read", "write", "admin"}}}},
+				{Name: "admin", Rules: []config.PermissionRule{{Verbs: []string{"read", "write", "admin"}}}},
+			},
+			RoleBindings: []config.RoleBinding{{Role: role, Users: []string{"token:ci"}}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := httptest.NewRequest("PUT", GetURLPrefix()+"api/test-library/scripts/cleanup", nil)
+		r.Header.Set("Authorization", "Bearer ci-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := request("server1-admin"); code != http.StatusForbidden || called != 0 {
+		t.Errorf("got status %v, expected an admin of a single server to be refused changing the library", code)
+	}
+	if code := request("admin"); code != http.StatusOK || called != 1 {
+		t.Errorf("got status %v, expected an admin of all servers to change the library", code)
+	}
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"github.com/sad0vnikov/radish/audit"
+	"github.com/sad0vnikov/radish/auth"
 	"github.com/sad0vnikov/radish/config"
 	"github.com/sad0vnikov/radish/http/api"
 	"github.com/sad0vnikov/radish/http/server"
 	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/monitoring"
 )
 
 func main() {
@@ -21,8 +24,17 @@ func main() {
 		panic(err)
 	}
 
+	err = auth.Init(config.Get().Auth)
+	if err != nil {
+		panic(err)
+	}
+	audit.Init(config.Get())
+
+	monitoring.Start()
+
 	server := server.HTTPServer{Port: 8080}
 	server.AddHandler("GET", api.Version()+"/servers", api.GetServersList)
+	server.AddHandler("GET", api.Version()+"/topology", api.GetTopology)
 	server.AddHandler("GET", api.Version()+"/servers/{server}/databasesCount", api.GetMaxDbNumber)
 	server.AddHandler("GET", api.Version()+"/servers/{server}/keys", api.GetKeysByMask)
 	server.AddHandler("GET", api.Version()+"/servers/{server}/keys/{key}/info", api.GetKeyInfo)
@@ -49,8 +61,91 @@ func main() {
 	server.AddHandler("PUT", api.Version()+"/servers/{server}/keys/zsets/{key}/values/{value}", api.UpdateZSetValue)
 	server.AddHandler("DELETE", api.Version()+"/servers/{server}/keys/zsets/{key}/values/{value}", api.DeleteZSetValue)
 
+	server.AddHandler("GET", api.Version()+"/servers/{server}/snapshots", api.GetSnapshots)
+	server.AddHandler("POST", api.Version()+"/servers/{server}/keys/{key}/snapshots/{id}/restore", api.RestoreSnapshot)
+
+	server.AddHandler("GET", api.Version()+"/servers/{server}/trash", api.GetTrash)
+	server.AddKeysHandler("POST", api.Version()+"/servers/{server}/trash/{id}/restore", api.RestoreTrashedKey)
+	server.AddKeysHandler("DELETE", api.Version()+"/servers/{server}/trash/{id}", api.PurgeTrashedKey)
+	server.AddAdminHandler("DELETE", api.Version()+"/servers/{server}/trash", api.PurgeTrash)
+
+	server.AddKeysHandler("POST", api.Version()+"/servers/{server}/batch", api.RunBatch)
+
+	server.AddAdminHandler("POST", api.Version()+"/servers/{server}/flushdb", api.FlushDB)
+	server.AddAdminHandler("POST", api.Version()+"/servers/{server}/flushall", api.FlushAll)
+	server.AddAdminHandler("POST", api.Version()+"/servers/{server}/swapdb", api.SwapDB)
+
+	server.AddServerHandler("GET", api.Version()+"/servers/{server}/slowlog", api.GetSlowlog)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/slowlog/len", api.GetSlowlogLen)
+	server.AddAdminHandler("DELETE", api.Version()+"/servers/{server}/slowlog", api.ResetSlowlog)
+
+	server.AddHandler("GET", api.Version()+"/servers/{server}/metrics", api.GetServerMetrics)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/info", api.GetServerInfo)
+
+	server.AddHandler("GET", api.Version()+"/servers/{server}/latency", api.GetLatencyLatest)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/latency/history/{event}", api.GetLatencyHistory)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/latency/doctor", api.GetLatencyDoctor)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/latency/probe", api.GetProbedLatency)
+	server.AddHandler("DELETE", api.Version()+"/servers/{server}/latency/probe", api.ResetProbedLatency)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/memory/doctor", api.GetMemoryDoctor)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/memory/stats", api.GetMemoryStats)
+
+	server.AddHandler("GET", api.Version()+"/servers/{server}/persistence", api.GetPersistenceStatus)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/persistence/lastsave", api.GetLastSave)
+	server.AddAdminHandler("POST", api.Version()+"/servers/{server}/persistence/bgsave", api.StartBackgroundSave)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/persistence/bgsave", api.GetBackgroundSaveProgress)
+	server.AddAdminHandler("POST", api.Version()+"/servers/{server}/persistence/bgrewriteaof", api.StartAOFRewrite)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/persistence/bgrewriteaof", api.GetAOFRewriteProgress)
+
+	server.AddServerHandler("GET", api.Version()+"/servers/{server}/clients", api.GetClients)
+	server.AddAdminHandler("POST", api.Version()+"/servers/{server}/clients/kill", api.KillClients)
+	server.AddAdminHandler("POST", api.Version()+"/servers/{server}/clients/pause", api.PauseClients)
+	server.AddAdminHandler("DELETE", api.Version()+"/servers/{server}/clients/pause", api.UnpauseClients)
+
+	server.AddHandler("GET", api.Version()+"/servers/{server}/config", api.GetServerConfig)
+	server.AddAdminHandler("PUT", api.Version()+"/servers/{server}/config/{param}", api.SetServerConfigParam)
+	server.AddAdminHandler("POST", api.Version()+"/servers/{server}/config/rewrite", api.RewriteServerConfig)
+
+	server.AddStreamHandler("GET", api.Version()+"/servers/{server}/monitor", api.MonitorServer)
+
+	server.AddCommandsHandler("POST", api.Version()+"/servers/{server}/console", api.ExecuteCommand)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/console/history", api.GetConsoleHistory)
+
+	server.AddCommandsHandler("POST", api.Version()+"/servers/{server}/scripts/eval", api.EvalScript)
+	server.AddHandler("POST", api.Version()+"/servers/{server}/scripts/load", api.LoadScript)
+	server.AddHandler("GET", api.Version()+"/servers/{server}/scripts/exists", api.ScriptsExist)
+	server.AddAdminHandler("DELETE", api.Version()+"/servers/{server}/scripts", api.FlushScripts)
+	server.AddCommandsHandler("POST", api.Version()+"/servers/{server}/scripts/saved/{name}/run", api.RunSavedScript)
+
+	server.AddHandler("GET", api.Version()+"/servers/{server}/functions", api.GetFunctions)
+	server.AddAdminHandler("POST", api.Version()+"/servers/{server}/functions", api.LoadFunctionLibrary)
+	server.AddAdminHandler("DELETE", api.Version()+"/servers/{server}/functions/{library}", api.DeleteFunctionLibrary)
+	server.AddCommandsHandler("POST", api.Version()+"/servers/{server}/functions/call/{function}", api.CallFunction)
+	server.AddAdminHandler("POST", api.Version()+"/servers/{server}/functions/copy", api.CopyFunctions)
+
+	server.AddServerHandler("GET", api.Version()+"/servers/{server}/acl/users", api.GetACLUsers)
+	server.AddServerHandler("GET", api.Version()+"/servers/{server}/acl/users/{user}", api.GetACLUser)
+	server.AddAdminHandler("PUT", api.Version()+"/servers/{server}/acl/users/{user}", api.SetACLUser)
+	server.AddAdminHandler("DELETE", api.Version()+"/servers/{server}/acl/users/{user}", api.DeleteACLUser)
+	server.AddHandler("POST", api.Version()+"/servers/{server}/acl/users/{user}/dryrun", api.ACLDryRun)
+	server.AddServerHandler("GET", api.Version()+"/servers/{server}/acl/log", api.GetACLLog)
+	server.AddAdminHandler("DELETE", api.Version()+"/servers/{server}/acl/log", api.ResetACLLog)
+
+	server.AddHandler("GET", api.Version()+"/scripts", api.GetSavedScripts)
+	server.AddHandler("GET", api.Version()+"/scripts/{name}", api.GetSavedScript)
+	server.AddLibraryHandler("PUT", api.Version()+"/scripts/{name}", api.SaveScript)
+	server.AddLibraryHandler("DELETE", api.Version()+"/scripts/{name}", api.DeleteSavedScript)
+
+	server.AddHandler("GET", api.Version()+"/audit", api.GetAuditLog)
+
 	server.AddHandler("GET", api.Version()+"/appVersion", api.GetAppVersion)
 
+	server.AddPublicHandler("POST", api.Version()+"/auth/login", api.Login)
+	server.AddPublicHandler("GET", api.Version()+"/auth/me", api.GetCurrentUser)
+	server.AddHandler("POST", api.Version()+"/auth/logout", api.Logout)
+	server.AddPublicHandler("GET", api.Version()+"/auth/oidc/login", api.StartOIDCLogin)
+	server.AddPublicStreamHandler("GET", api.Version()+"/auth/oidc/callback", api.FinishOIDCLogin)
+
 	server.ServeStatic()
 	server.Init()
 
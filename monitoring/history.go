@@ -0,0 +1,66 @@
+package monitoring
+
+import (
+	"sync"
+	"time"
+)
+
+//Sample is a snapshot of Redis instance load
+type Sample struct {
+	Time              int64
+	OpsPerSec         int64
+	UsedMemoryBytes   int64
+	ConnectedClients  int64
+	BlockedClients    int64
+	HitRatio          float64
+	EvictedKeys       int64
+	NetInputKbps      float64
+	NetOutputKbps     float64
+	ReplicationOffset int64
+}
+
+//History is a bounded ring buffer of load samples
+type History struct {
+	mu      sync.RWMutex
+	samples []Sample
+	next    int
+	full    bool
+}
+
+//NewHistory returns a History keeping at most size samples
+func NewHistory(size int) *History {
+	return &History{samples: make([]Sample, size)}
+}
+
+//Add appends a sample to the history, overwriting the oldest one if the buffer is full
+func (h *History) Add(s Sample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.samples[h.next] = s
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+//Since returns samples taken not earlier than given time, the oldest go first
+func (h *History) Since(t time.Time) []Sample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ordered := h.samples[:h.next]
+	if h.full {
+		ordered = append(append([]Sample{}, h.samples[h.next:]...), h.samples[:h.next]...)
+	}
+
+	since := t.Unix()
+	result := []Sample{}
+	for _, s := range ordered {
+		if s.Time >= since {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
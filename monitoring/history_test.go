@@ -0,0 +1,56 @@
+package monitoring
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+func TestHistoryKeepsLatestSamples(t *testing.T) {
+	h := NewHistory(3)
+	for i := int64(1); i <= 5; i++ {
+		h.Add(Sample{Time: i, OpsPerSec: i * 10})
+	}
+
+	result := h.Since(time.Unix(0, 0))
+	expected := []Sample{
+		Sample{Time: 3, OpsPerSec: 30},
+		Sample{Time: 4, OpsPerSec: 40},
+		Sample{Time: 5, OpsPerSec: 50},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got samples %v, expected %v", result, expected)
+	}
+
+	result = h.Since(time.Unix(5, 0))
+	if len(result) != 1 || result[0].Time != 5 {
+		t.Errorf("got samples %v, expected only the last one", result)
+	}
+}
+
+func TestNewSampleUsesCountersDeltas(t *testing.T) {
+	info := rd.ServerInfo{}
+	info.Stats.InstantaneousOpsPerSec = 120
+	info.Stats.InstantaneousInputKbps = 1.5
+	info.Stats.KeyspaceHits = 180
+	info.Stats.KeyspaceMisses = 20
+	info.Stats.EvictedKeys = 15
+	info.Memory.UsedMemory = 1048576
+	info.Clients.ConnectedClients = 7
+	info.Replication.MasterReplOffset = 4242
+
+	s, cur := newSample(info, nil)
+	if s.HitRatio != 0.9 || s.EvictedKeys != 0 || s.OpsPerSec != 120 || s.ReplicationOffset != 4242 || s.NetInputKbps != 1.5 {
+		t.Errorf("got invalid first sample %+v", s)
+	}
+
+	info.Stats.KeyspaceHits = 210
+	info.Stats.KeyspaceMisses = 30
+	info.Stats.EvictedKeys = 18
+	s, _ = newSample(info, &cur)
+	if s.HitRatio != 0.75 || s.EvictedKeys != 3 {
+		t.Errorf("got invalid sample %+v, expected deltas to be used", s)
+	}
+}
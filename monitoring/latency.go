@@ -0,0 +1,176 @@
+package monitoring
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	//histogram buckets grow exponentially from 10 microseconds to about 30 seconds
+	latencyBucketsCount  = 70
+	latencyBucketMin     = 10 * time.Microsecond
+	latencyBucketsFactor = 1.25
+)
+
+var latencyBucketBounds = newLatencyBucketBounds()
+
+func newLatencyBucketBounds() []time.Duration {
+	bounds := make([]time.Duration, latencyBucketsCount)
+	bound := float64(latencyBucketMin)
+	for i := range bounds {
+		bounds[i] = time.Duration(bound)
+		bound *= latencyBucketsFactor
+	}
+	return bounds
+}
+
+//LatencyBucket is a count of round trips not longer than UpperBoundMicroseconds
+//the last bucket has no upper bound and its UpperBoundMicroseconds is -1
+type LatencyBucket struct {
+	UpperBoundMicroseconds int64
+	Count                  int64
+}
+
+//LatencyStats is a summary of round-trip times measured by the latency prober
+type LatencyStats struct {
+	Since            int64
+	Count            int64
+	Failures         int64
+	LastMicroseconds int64
+	MinMicroseconds  int64
+	MaxMicroseconds  int64
+	MeanMicroseconds int64
+	P50Microseconds  int64
+	P90Microseconds  int64
+	P99Microseconds  int64
+	P999Microseconds int64
+	Buckets          []LatencyBucket
+}
+
+//LatencyHistogram accumulates round-trip times in exponential buckets
+type LatencyHistogram struct {
+	mu       sync.RWMutex
+	since    time.Time
+	counts   []int64
+	count    int64
+	failures int64
+	sum      time.Duration
+	min      time.Duration
+	max      time.Duration
+	last     time.Duration
+}
+
+//NewLatencyHistogram returns an empty histogram
+func NewLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{since: time.Now(), counts: make([]int64, latencyBucketsCount+1)}
+}
+
+//Add records a round-trip time
+func (h *LatencyHistogram) Add(rtt time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := 0
+	for i < latencyBucketsCount && rtt > latencyBucketBounds[i] {
+		i++
+	}
+	h.counts[i]++
+
+	if h.count == 0 || rtt < h.min {
+		h.min = rtt
+	}
+	if rtt > h.max {
+		h.max = rtt
+	}
+	h.count++
+	h.sum += rtt
+	h.last = rtt
+}
+
+//AddFailure records a failed probe
+func (h *LatencyHistogram) AddFailure() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.failures++
+}
+
+//Reset clears the histogram
+func (h *LatencyHistogram) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.since = time.Now()
+	h.counts = make([]int64, latencyBucketsCount+1)
+	h.count, h.failures = 0, 0
+	h.sum, h.min, h.max, h.last = 0, 0, 0, 0
+}
+
+//Percentile returns an upper bound of round-trip time p percent of probes didn't exceed
+func (h *LatencyHistogram) Percentile(p float64) time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.percentile(p)
+}
+
+func (h *LatencyHistogram) percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(p / 100 * float64(h.count)))
+	var seen int64
+	for i, count := range h.counts {
+		seen += count
+		if seen < rank {
+			continue
+		}
+		if i == latencyBucketsCount || latencyBucketBounds[i] > h.max {
+			return h.max
+		}
+		return latencyBucketBounds[i]
+	}
+	return h.max
+}
+
+//Stats returns the histogram summary, only non-empty buckets are included
+func (h *LatencyHistogram) Stats() LatencyStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats := LatencyStats{
+		Since:            h.since.Unix(),
+		Count:            h.count,
+		Failures:         h.failures,
+		LastMicroseconds: microseconds(h.last),
+		MinMicroseconds:  microseconds(h.min),
+		MaxMicroseconds:  microseconds(h.max),
+		P50Microseconds:  microseconds(h.percentile(50)),
+		P90Microseconds:  microseconds(h.percentile(90)),
+		P99Microseconds:  microseconds(h.percentile(99)),
+		P999Microseconds: microseconds(h.percentile(99.9)),
+		Buckets:          []LatencyBucket{},
+	}
+	if h.count > 0 {
+		stats.MeanMicroseconds = microseconds(h.sum / time.Duration(h.count))
+	}
+
+	for i, count := range h.counts {
+		if count == 0 {
+			continue
+		}
+		bucket := LatencyBucket{UpperBoundMicroseconds: -1, Count: count}
+		if i < latencyBucketsCount {
+			bucket.UpperBoundMicroseconds = microseconds(latencyBucketBounds[i])
+		}
+		stats.Buckets = append(stats.Buckets, bucket)
+	}
+
+	return stats
+}
+
+func microseconds(d time.Duration) int64 {
+	return d.Nanoseconds() / 1000
+}
@@ -0,0 +1,39 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogramPercentiles(t *testing.T) {
+	h := NewLatencyHistogram()
+	for i := 0; i < 99; i++ {
+		h.Add(200 * time.Microsecond)
+	}
+	h.Add(40 * time.Millisecond)
+	h.AddFailure()
+
+	p50 := h.Percentile(50)
+	if p50 < 200*time.Microsecond || p50 > 250*time.Microsecond {
+		t.Errorf("got p50 %v, expected about 200µs", p50)
+	}
+	if p := h.Percentile(99); p > 250*time.Microsecond {
+		t.Errorf("got p99 %v, expected about 200µs", p)
+	}
+	if p := h.Percentile(100); p != 40*time.Millisecond {
+		t.Errorf("got p100 %v, expected 40ms", p)
+	}
+
+	stats := h.Stats()
+	if stats.Count != 100 || stats.Failures != 1 || stats.MaxMicroseconds != 40000 || stats.MinMicroseconds != 200 {
+		t.Errorf("got invalid stats %+v", stats)
+	}
+	if len(stats.Buckets) != 2 || stats.Buckets[0].Count != 99 {
+		t.Errorf("got invalid buckets %+v", stats.Buckets)
+	}
+
+	h.Reset()
+	if stats := h.Stats(); stats.Count != 0 || len(stats.Buckets) != 0 {
+		t.Errorf("got stats %+v after reset, expected empty stats", stats)
+	}
+}
@@ -0,0 +1,54 @@
+package monitoring
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sad0vnikov/radish/logger"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+var (
+	latenciesMu sync.RWMutex
+	latencies   = make(map[string]*LatencyHistogram)
+)
+
+//startProbers runs a background PING round-trip prober for every server
+func startProbers(servers []string, interval time.Duration) {
+	latenciesMu.Lock()
+	defer latenciesMu.Unlock()
+	for _, name := range servers {
+		histogram := NewLatencyHistogram()
+		latencies[name] = histogram
+		go probe(name, interval, histogram)
+	}
+}
+
+//GetLatency returns round-trip times histogram for a given server
+func GetLatency(serverName string) (*LatencyHistogram, bool) {
+	latenciesMu.RLock()
+	defer latenciesMu.RUnlock()
+
+	histogram, prs := latencies[serverName]
+	return histogram, prs
+}
+
+func probe(serverName string, interval time.Duration, histogram *LatencyHistogram) {
+	p := db.NewLatencyProbe(serverName)
+	defer p.Close()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		rtt, err := p.Ping()
+		if err != nil {
+			logger.Info(fmt.Sprintf("can't probe server %v latency: %v", serverName, err))
+			histogram.AddFailure()
+		} else {
+			histogram.Add(rtt)
+		}
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,109 @@
+package monitoring
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sad0vnikov/radish/config"
+	"github.com/sad0vnikov/radish/logger"
+	rd "github.com/sad0vnikov/radish/redis"
+	"github.com/sad0vnikov/radish/redis/db"
+)
+
+var (
+	historiesMu sync.RWMutex
+	histories   = make(map[string]*History)
+)
+
+//counters are cumulative INFO values used to calculate per-interval metrics
+type counters struct {
+	keyspaceHits   int64
+	keyspaceMisses int64
+	evictedKeys    int64
+}
+
+//Start runs a background load sampler and a latency prober for every configured server
+func Start() {
+	c := config.Get()
+	interval := time.Duration(c.MonitoringInterval) * time.Second
+
+	servers := make([]string, 0, len(c.Servers))
+	historiesMu.Lock()
+	defer historiesMu.Unlock()
+	for name := range c.Servers {
+		history := NewHistory(c.MonitoringHistorySize)
+		histories[name] = history
+		go sample(name, interval, history)
+		servers = append(servers, name)
+	}
+
+	startProbers(servers, time.Duration(c.LatencyProbeInterval)*time.Second)
+}
+
+//GetHistory returns load history for a given server
+func GetHistory(serverName string) (*History, bool) {
+	historiesMu.RLock()
+	defer historiesMu.RUnlock()
+
+	history, prs := histories[serverName]
+	return history, prs
+}
+
+func sample(serverName string, interval time.Duration, history *History) {
+	var prev *counters
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		info, err := db.GetServerInfo(serverName, "default")
+		if err != nil {
+			logger.Info(fmt.Sprintf("can't sample server %v load: %v", serverName, err))
+			prev = nil
+		} else {
+			s, cur := newSample(info, prev)
+			s.Time = time.Now().Unix()
+			history.Add(s)
+			prev = &cur
+		}
+
+		<-ticker.C
+	}
+}
+
+func newSample(info rd.ServerInfo, prev *counters) (Sample, counters) {
+	cur := counters{
+		keyspaceHits:   info.Stats.KeyspaceHits,
+		keyspaceMisses: info.Stats.KeyspaceMisses,
+		evictedKeys:    info.Stats.EvictedKeys,
+	}
+
+	s := Sample{
+		OpsPerSec:         info.Stats.InstantaneousOpsPerSec,
+		UsedMemoryBytes:   info.Memory.UsedMemory,
+		ConnectedClients:  info.Clients.ConnectedClients,
+		BlockedClients:    info.Clients.BlockedClients,
+		NetInputKbps:      info.Stats.InstantaneousInputKbps,
+		NetOutputKbps:     info.Stats.InstantaneousOutputKbps,
+		ReplicationOffset: info.Replication.MasterReplOffset,
+	}
+	if s.ReplicationOffset == 0 {
+		s.ReplicationOffset = info.Replication.SlaveReplOffset
+	}
+
+	hits, misses := cur.keyspaceHits, cur.keyspaceMisses
+	//counters are reset on server restart, so the deltas are used only if they make sense
+	if prev != nil && cur.keyspaceHits >= prev.keyspaceHits && cur.keyspaceMisses >= prev.keyspaceMisses {
+		hits -= prev.keyspaceHits
+		misses -= prev.keyspaceMisses
+	}
+	if hits+misses > 0 {
+		s.HitRatio = float64(hits) / float64(hits+misses)
+	}
+
+	if prev != nil && cur.evictedKeys >= prev.evictedKeys {
+		s.EvictedKeys = cur.evictedKeys - prev.evictedKeys
+	}
+
+	return s, cur
+}
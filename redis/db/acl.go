@@ -0,0 +1,310 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+//ACLUser is a user as returned by ACL LIST
+type ACLUser struct {
+	Name    string
+	Enabled bool
+	Rules   []string
+}
+
+//ACLUserDetails is a user description returned by ACL GETUSER
+type ACLUserDetails struct {
+	Name      string
+	Flags     []string
+	Passwords []string
+	Commands  string
+	Keys      string
+	Channels  string
+	Selectors []ACLSelector
+}
+
+//ACLSelector is an additional set of permissions of a user
+type ACLSelector struct {
+	Commands string
+	Keys     string
+	Channels string
+}
+
+//ACLLogEntry is a denied command or authentication failure logged by Redis
+type ACLLogEntry struct {
+	EntryID              int64
+	Count                int64
+	Reason               string
+	Context              string
+	Object               string
+	Username             string
+	AgeSeconds           string
+	ClientInfo           string
+	TimestampCreated     int64
+	TimestampLastUpdated int64
+}
+
+//ACLDryRunResult tells if a user may execute a command
+type ACLDryRunResult struct {
+	Allowed bool
+	Reason  string `json:",omitempty"`
+}
+
+func aclConn(serverName string) (redis.Conn, string, error) {
+	command, err := serverCommand(serverName, "ACL")
+	if err != nil {
+		return nil, "", err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	return conn, command, err
+}
+
+//GetACLUsers returns users with their rules
+func GetACLUsers(serverName string) ([]ACLUser, error) {
+	conn, command, err := aclConn(serverName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	lines, err := redis.Strings(conn.Do(command, "LIST"))
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]ACLUser, 0, len(lines))
+	for _, line := range lines {
+		if user, ok := parseACLUser(line); ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+//parseACLUser parses ACL LIST lines like "user default on nopass ~* &* +@all"
+func parseACLUser(line string) (ACLUser, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "user" {
+		return ACLUser{}, false
+	}
+
+	user := ACLUser{Name: fields[1], Rules: fields[2:]}
+	for _, rule := range user.Rules {
+		if rule == "on" {
+			user.Enabled = true
+		}
+	}
+	return user, true
+}
+
+//GetACLUser returns a user description, ACLUserNotFoundError is returned if there is no such user
+func GetACLUser(serverName, name string) (ACLUserDetails, error) {
+	conn, command, err := aclConn(serverName)
+	if err != nil {
+		return ACLUserDetails{}, err
+	}
+	defer conn.Close()
+
+	r, err := conn.Do(command, "GETUSER", name)
+	if err != nil {
+		return ACLUserDetails{}, err
+	}
+	if r == nil {
+		return ACLUserDetails{}, ACLUserNotFoundError{Name: name}
+	}
+
+	fields, err := redis.Values(r, nil)
+	if err != nil {
+		return ACLUserDetails{}, err
+	}
+
+	user := ACLUserDetails{Name: name, Flags: []string{}, Passwords: []string{}, Selectors: []ACLSelector{}}
+	for i := 0; i+1 < len(fields); i += 2 {
+		field, _ := redis.String(fields[i], nil)
+		switch field {
+		case "flags":
+			user.Flags, _ = redis.Strings(fields[i+1], nil)
+		case "passwords":
+			user.Passwords, _ = redis.Strings(fields[i+1], nil)
+		case "commands":
+			user.Commands = aclRulesString(fields[i+1])
+		case "keys":
+			user.Keys = aclRulesString(fields[i+1])
+		case "channels":
+			user.Channels = aclRulesString(fields[i+1])
+		case "selectors":
+			selectors, _ := redis.Values(fields[i+1], nil)
+			for _, s := range selectors {
+				user.Selectors = append(user.Selectors, parseACLSelector(s))
+			}
+		}
+	}
+
+	return user, nil
+}
+
+func parseACLSelector(r interface{}) ACLSelector {
+	fields, _ := redis.Values(r, nil)
+	selector := ACLSelector{}
+	for i := 0; i+1 < len(fields); i += 2 {
+		field, _ := redis.String(fields[i], nil)
+		switch field {
+		case "commands":
+			selector.Commands = aclRulesString(fields[i+1])
+		case "keys":
+			selector.Keys = aclRulesString(fields[i+1])
+		case "channels":
+			selector.Channels = aclRulesString(fields[i+1])
+		}
+	}
+	return selector
+}
+
+//aclRulesString handles both Redis 7 string and Redis 6 list forms of keys and channels rules
+func aclRulesString(r interface{}) string {
+	if s, err := redis.String(r, nil); err == nil {
+		return s
+	}
+
+	rules, _ := redis.Strings(r, nil)
+	return strings.Join(rules, " ")
+}
+
+//ACLUserNotFoundError is returned when there is no user with given name
+type ACLUserNotFoundError struct {
+	Name string
+}
+
+func (err ACLUserNotFoundError) Error() string {
+	return "user " + err.Name + " not found"
+}
+
+//SetACLUser creates a user or modifies an existing user applying given rules
+func SetACLUser(serverName, name string, rules []string) error {
+	conn, command, err := aclConn(serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	args := []interface{}{"SETUSER", name}
+	for _, rule := range rules {
+		args = append(args, rule)
+	}
+
+	_, err = conn.Do(command, args...)
+	return err
+}
+
+//DeleteACLUser deletes a user, ACLUserNotFoundError is returned if there is no such user
+func DeleteACLUser(serverName, name string) error {
+	conn, command, err := aclConn(serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deleted, err := redis.Int64(conn.Do(command, "DELUSER", name))
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ACLUserNotFoundError{Name: name}
+	}
+	return nil
+}
+
+//GetACLLog returns count latest ACL log entries
+func GetACLLog(serverName string, count int) ([]ACLLogEntry, error) {
+	conn, command, err := aclConn(serverName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	r, err := redis.Values(conn.Do(command, "LOG", count))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ACLLogEntry, 0, len(r))
+	for _, entryReply := range r {
+		fields, err := redis.Values(entryReply, nil)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, parseACLLogEntry(fields))
+	}
+	return entries, nil
+}
+
+func parseACLLogEntry(fields []interface{}) ACLLogEntry {
+	entry := ACLLogEntry{}
+	for i := 0; i+1 < len(fields); i += 2 {
+		field, _ := redis.String(fields[i], nil)
+		value := fields[i+1]
+		switch field {
+		case "count":
+			entry.Count, _ = redis.Int64(value, nil)
+		case "reason":
+			entry.Reason, _ = redis.String(value, nil)
+		case "context":
+			entry.Context, _ = redis.String(value, nil)
+		case "object":
+			entry.Object, _ = redis.String(value, nil)
+		case "username":
+			entry.Username, _ = redis.String(value, nil)
+		case "age-seconds":
+			entry.AgeSeconds, _ = redis.String(value, nil)
+		case "client-info":
+			entry.ClientInfo, _ = redis.String(value, nil)
+		case "entry-id":
+			entry.EntryID, _ = redis.Int64(value, nil)
+		case "timestamp-created":
+			entry.TimestampCreated, _ = redis.Int64(value, nil)
+		case "timestamp-last-updated":
+			entry.TimestampLastUpdated, _ = redis.Int64(value, nil)
+		}
+	}
+	return entry
+}
+
+//ResetACLLog clears ACL log
+func ResetACLLog(serverName string) error {
+	conn, command, err := aclConn(serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do(command, "LOG", "RESET")
+	return err
+}
+
+//ACLDryRun checks if a user may execute a command without executing it
+func ACLDryRun(serverName, user string, args []string) (ACLDryRunResult, error) {
+	conn, command, err := aclConn(serverName)
+	if err != nil {
+		return ACLDryRunResult{}, err
+	}
+	defer conn.Close()
+
+	commandArgs := []interface{}{"DRYRUN", user}
+	for _, arg := range args {
+		commandArgs = append(commandArgs, arg)
+	}
+
+	r, err := conn.Do(command, commandArgs...)
+	if err != nil {
+		return ACLDryRunResult{}, err
+	}
+
+	if status, ok := r.(string); ok && status == "OK" {
+		return ACLDryRunResult{Allowed: true}, nil
+	}
+
+	reason, err := redis.String(r, nil)
+	return ACLDryRunResult{Reason: reason}, err
+}
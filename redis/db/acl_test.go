@@ -0,0 +1,70 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+)
+
+func TestGettingACLUsers(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("ACL", "LIST").ExpectSlice(
+		"user default on nopass ~* &* +@all",
+		"user reader off #5e88 ~cache:* resetchannels -@all +get",
+	)
+
+	users, err := GetACLUsers("server1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ACLUser{
+		{Name: "default", Enabled: true, Rules: []string{"on", "nopass", "~*", "&*", "+@all"}},
+		{Name: "reader", Enabled: false, Rules: []string{"off", "#5e88", "~cache:*", "resetchannels", "-@all", "+get"}},
+	}
+	if !reflect.DeepEqual(users, expected) {
+		t.Errorf("got users %+v, expected %+v", users, expected)
+	}
+}
+
+func TestGettingACLUser(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("ACL", "GETUSER", "reader").Expect([]interface{}{
+		[]byte("flags"), []interface{}{[]byte("on")},
+		[]byte("passwords"), []interface{}{},
+		[]byte("commands"), []byte("-@all +get"),
+		[]byte("keys"), []byte("~cache:*"),
+		[]byte("channels"), []byte(""),
+		[]byte("selectors"), []interface{}{
+			[]interface{}{[]byte("commands"), []byte("+set"), []byte("keys"), []byte("~tmp:*"), []byte("channels"), []byte("")},
+		},
+	})
+	conn.Command("ACL", "GETUSER", "nobody").Expect(nil)
+
+	user, err := GetACLUser("server1", "reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ACLUserDetails{
+		Name:      "reader",
+		Flags:     []string{"on"},
+		Passwords: []string{},
+		Commands:  "-@all +get",
+		Keys:      "~cache:*",
+		Selectors: []ACLSelector{{Commands: "+set", Keys: "~tmp:*"}},
+	}
+	if !reflect.DeepEqual(user, expected) {
+		t.Errorf("got user %+v, expected %+v", user, expected)
+	}
+
+	if _, err := GetACLUser("server1", "nobody"); err == nil {
+		t.Error("expected an error for missing user")
+	}
+}
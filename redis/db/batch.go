@@ -0,0 +1,200 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+//Batch operation types
+const (
+	BatchSet    = "set"
+	BatchHSet   = "hset"
+	BatchHDel   = "hdel"
+	BatchLPush  = "lpush"
+	BatchSAdd   = "sadd"
+	BatchSRem   = "srem"
+	BatchZAdd   = "zadd"
+	BatchZRem   = "zrem"
+	BatchExpire = "expire"
+	BatchDel    = "del"
+)
+
+//BatchOperation is a typed change of a key executed as a part of a batch
+//Field is a hash field for hset and hdel, Value is a string value or a member for other operations except expire and del,
+//Score is a sorted set member score for zadd and Seconds is a key time to live for expire
+type BatchOperation struct {
+	Op      string
+	Key     string
+	Field   string
+	Value   string
+	Score   int64
+	Seconds int64
+}
+
+//BatchResult is a result of a batch operation, Reply is a Redis reply to the operation command if it succeeded
+type BatchResult struct {
+	Reply interface{} `json:",omitempty"`
+	Error string      `json:",omitempty"`
+}
+
+//InvalidBatchOperationError is returned for operations of unknown types or without required params
+type InvalidBatchOperationError struct {
+	//Index is the operation number in the batch starting from 0
+	Index  int
+	Reason string
+}
+
+func (err InvalidBatchOperationError) Error() string {
+	return fmt.Sprintf("operation #%v is invalid: %v", err.Index+1, err.Reason)
+}
+
+func (op BatchOperation) command() (command, string) {
+	if len(op.Key) == 0 {
+		return command{}, "key is missing"
+	}
+
+	switch op.Op {
+	case BatchSet:
+		return newCommand("SET", op.Key, op.Value), ""
+	case BatchHSet:
+		if len(op.Field) == 0 {
+			return command{}, "hash field is missing"
+		}
+		return newCommand("HSET", op.Key, op.Field, op.Value), ""
+	case BatchHDel:
+		if len(op.Field) == 0 {
+			return command{}, "hash field is missing"
+		}
+		return newCommand("HDEL", op.Key, op.Field), ""
+	case BatchLPush:
+		return newCommand("LPUSH", op.Key, op.Value), ""
+	case BatchSAdd:
+		return newCommand("SADD", op.Key, op.Value), ""
+	case BatchSRem:
+		return newCommand("SREM", op.Key, op.Value), ""
+	case BatchZAdd:
+		return newCommand("ZADD", op.Key, op.Score, op.Value), ""
+	case BatchZRem:
+		return newCommand("ZREM", op.Key, op.Value), ""
+	case BatchExpire:
+		if op.Seconds <= 0 {
+			return command{}, "time to live must be positive"
+		}
+		return newCommand("EXPIRE", op.Key, op.Seconds), ""
+	case BatchDel:
+		return newCommand("DEL", op.Key), ""
+	}
+	return command{}, "unknown operation type '" + op.Op + "'"
+}
+
+//ValidateBatch returns InvalidBatchOperationError if any of operations has unknown type or misses required params
+func ValidateBatch(ops []BatchOperation) error {
+	_, err := batchCommands(ops)
+	return err
+}
+
+func batchCommands(ops []BatchOperation) ([]command, error) {
+	commands := make([]command, len(ops))
+	for i, op := range ops {
+		c, reason := op.command()
+		if len(reason) > 0 {
+			return nil, InvalidBatchOperationError{Index: i, Reason: reason}
+		}
+		commands[i] = c
+	}
+	return commands, nil
+}
+
+//RunBatch executes operations on a database in the given order and returns their results
+//operations are pipelined on a single connection, if transactional is true they are executed within MULTI/EXEC
+//and none of them is executed if Redis rejects any of the commands
+//changed keys are saved as snapshots before the batch is executed
+func RunBatch(serverName string, dbNum uint8, ops []BatchOperation, transactional bool) ([]BatchResult, error) {
+	commands, err := batchCommands(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := connector.GetByName(serverName, dbNum)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	snapshotted := make(map[string]bool)
+	for _, op := range ops {
+		if snapshotted[op.Key] {
+			continue
+		}
+		err = snapshotKey(conn, serverName, dbNum, op.Key, "batch "+op.Op)
+		if err != nil {
+			return nil, err
+		}
+		snapshotted[op.Key] = true
+	}
+
+	if transactional {
+		return runTransactionalBatch(conn, commands)
+	}
+	return runPipelinedBatch(conn, commands)
+}
+
+func runPipelinedBatch(conn redis.Conn, commands []command) ([]BatchResult, error) {
+	for _, c := range commands {
+		err := conn.Send(c.name, c.args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := conn.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(commands))
+	for i := range commands {
+		reply, err := conn.Receive()
+		if _, ok := err.(redis.Error); err != nil && !ok {
+			return nil, err
+		}
+		results[i] = newBatchResult(reply, err)
+	}
+	return results, nil
+}
+
+func runTransactionalBatch(conn redis.Conn, commands []command) ([]BatchResult, error) {
+	replies, err := execTransaction(conn, commands)
+	cerr, failed := err.(TransactionCommandError)
+	if err != nil && !failed {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(commands))
+	if replies == nil && failed {
+		for i := range results {
+			results[i].Error = "not executed, the transaction is discarded"
+		}
+		results[cerr.Index].Error = cerr.Err.Error()
+		return results, nil
+	}
+
+	for i, reply := range replies {
+		if rerr, ok := reply.(redis.Error); ok {
+			results[i] = newBatchResult(nil, rerr)
+		} else {
+			results[i] = newBatchResult(reply, nil)
+		}
+	}
+	return results, nil
+}
+
+func newBatchResult(reply interface{}, err error) BatchResult {
+	if err != nil {
+		return BatchResult{Error: err.Error()}
+	}
+	if b, ok := reply.([]byte); ok {
+		reply = string(b)
+	}
+	return BatchResult{Reply: reply}
+}
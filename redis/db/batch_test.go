@@ -0,0 +1,184 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+)
+
+var testBatch = []BatchOperation{
+	{Op: BatchHSet, Key: "user:1", Field: "name", Value: "alice"},
+	{Op: BatchSAdd, Key: "user:1", Value: "admin"},
+	{Op: BatchExpire, Key: "user:1", Seconds: 60},
+}
+
+func expectTestBatchSnapshot(conn *redigomock.Conn) {
+	conn.Command("DUMP", "user:1").Expect([]byte("dumped"))
+	conn.Command("TYPE", "user:1").Expect("hash")
+	conn.Command("PTTL", "user:1").Expect(int64(-1))
+}
+
+func TestRunningPipelinedBatch(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	expectTestBatchSnapshot(conn)
+	conn.Command("HSET", "user:1", "name", "alice").Expect(int64(1))
+	conn.Command("SADD", "user:1", "admin").ExpectError(redis.Error("WRONGTYPE Operation against a key holding the wrong kind of value"))
+	conn.Command("EXPIRE", "user:1", int64(60)).Expect(int64(1))
+
+	results, err := RunBatch("server1", 0, testBatch, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []BatchResult{
+		{Reply: int64(1)},
+		{Error: "WRONGTYPE Operation against a key holding the wrong kind of value"},
+		{Reply: int64(1)},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("got results %+v, expected %+v", results, expected)
+	}
+
+	list := GetSnapshots("server1", nil, "user:1")
+	if len(list) != 1 {
+		t.Errorf("got %v snapshots, expected a single snapshot of a changed key", len(list))
+	}
+}
+
+//countingConnections counts connections taken from the mocked connector
+type countingConnections struct {
+	MockedConnections
+	count int
+}
+
+func (connections *countingConnections) GetByName(serverName string, dbNum uint8) (redis.Conn, error) {
+	connections.count++
+	return connections.MockedConnections.GetByName(serverName, dbNum)
+}
+
+func TestRunningBatchOnSingleConnection(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	closed := 0
+	conn.CloseMock = func() error {
+		closed++
+		return nil
+	}
+	counting := &countingConnections{MockedConnections: MockedConnections{ConnectionMock: conn}}
+	connector = counting
+	expectTestBatchSnapshot(conn)
+	conn.Command("HSET", "user:1", "name", "alice").Expect(int64(1))
+	conn.Command("SADD", "user:1", "admin").Expect(int64(1))
+	conn.Command("EXPIRE", "user:1", int64(60)).Expect(int64(1))
+
+	_, err := RunBatch("server1", 0, testBatch, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counting.count != 1 || closed != 1 {
+		t.Errorf("got %v connections and %v of them closed, expected the batch and its snapshots to use a single closed connection", counting.count, closed)
+	}
+}
+
+func TestRunningTransactionalBatch(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	expectTestBatchSnapshot(conn)
+	multi := conn.Command("MULTI").Expect("OK")
+	conn.Command("HSET", "user:1", "name", "alice").Expect("QUEUED")
+	conn.Command("SADD", "user:1", "admin").Expect("QUEUED")
+	conn.Command("EXPIRE", "user:1", int64(60)).Expect("QUEUED")
+	conn.Command("EXEC").Expect([]interface{}{int64(1), redis.Error("WRONGTYPE Operation against a key holding the wrong kind of value"), int64(1)})
+
+	results, err := RunBatch("server1", 0, testBatch, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []BatchResult{
+		{Reply: int64(1)},
+		{Error: "WRONGTYPE Operation against a key holding the wrong kind of value"},
+		{Reply: int64(1)},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("got results %+v, expected %+v", results, expected)
+	}
+	if conn.Stats(multi) != 1 {
+		t.Errorf("expected the batch to be executed within MULTI/EXEC")
+	}
+}
+
+func TestReportingDiscardedTransactionalBatch(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	expectTestBatchSnapshot(conn)
+	conn.Command("MULTI").Expect("OK")
+	conn.Command("HSET", "user:1", "name", "alice").Expect("QUEUED")
+	conn.Command("SADD", "user:1", "admin").ExpectError(redis.Error("ERR command is not allowed"))
+	conn.Command("DISCARD").Expect("OK")
+
+	results, err := RunBatch("server1", 0, testBatch, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[1].Error != "ERR command is not allowed" || results[0].Error == "" || results[2].Error == "" {
+		t.Errorf("got results %+v, expected SADD error and the other operations not to be executed", results)
+	}
+}
+
+func TestValidatingBatch(t *testing.T) {
+	cases := []struct {
+		ops   []BatchOperation
+		valid bool
+	}{
+		{testBatch, true},
+		{[]BatchOperation{{Op: BatchDel, Key: "user:1"}, {Op: "rename", Key: "user:1"}}, false},
+		{[]BatchOperation{{Op: BatchSet, Value: "alice"}}, false},
+		{[]BatchOperation{{Op: BatchHDel, Key: "user:1"}}, false},
+		{[]BatchOperation{{Op: BatchExpire, Key: "user:1"}}, false},
+	}
+
+	for _, c := range cases {
+		err := ValidateBatch(c.ops)
+		if _, invalid := err.(InvalidBatchOperationError); invalid == c.valid {
+			t.Errorf("got error %v validating %+v, expected it to be valid: %v", err, c.ops, c.valid)
+		}
+	}
+}
+
+func TestClosingTakenConnections(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+
+	conn := redigomock.NewConn()
+	closed := 0
+	conn.CloseMock = func() error {
+		closed++
+		return nil
+	}
+	counting := &countingConnections{MockedConnections: MockedConnections{ConnectionMock: conn}}
+	connector = counting
+	conn.Command("SLOWLOG", "LEN").Expect(int64(3))
+	conn.Command("EXISTS", "user:1").Expect(int64(1))
+	conn.Command("HEXISTS", "user:1", "name").Expect(int64(1))
+
+	GetSlowlogLen("server1")
+	KeyExists("server1", 0, "user:1")
+	HashKeyExists("server1", 0, "user:1", "name")
+
+	if counting.count != 3 || closed != 3 {
+		t.Errorf("got %v connections and %v of them closed, expected every connection to be returned to the pool", counting.count, closed)
+	}
+}
@@ -0,0 +1,256 @@
+package db
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/sad0vnikov/radish/logger"
+)
+
+//ClientInfo is a parsed CLIENT LIST row
+type ClientInfo struct {
+	ID       int64
+	Addr     string
+	LAddr    string
+	Name     string
+	Age      int64
+	Idle     int64
+	DB       int64
+	Flags    string
+	Cmd      string
+	User     string
+	QBuf     int64
+	QBufFree int64
+	OBL      int64
+	OLL      int64
+	OMem     int64
+}
+
+//ClientsQuery is a filter and sorting order for clients list
+//Addr and Name are masks, a client should have all the Flags given
+type ClientsQuery struct {
+	Addr   string
+	Name   string
+	Flags  string
+	Cmd    string
+	User   string
+	DB     *int64
+	SortBy string
+	Desc   bool
+}
+
+//ClientKillFilter selects clients to kill, at least one field should be set
+type ClientKillFilter struct {
+	ID   int64
+	Addr string
+	User string
+}
+
+//GetClients returns a list of clients connected to a server
+func GetClients(serverName string) ([]ClientInfo, error) {
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	r, err := conn.Do("CLIENT", "LIST")
+	list, err := redis.String(r, err)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return parseClientList(list), nil
+}
+
+//QueryClients returns clients matching query sorted in query order
+func QueryClients(clients []ClientInfo, query ClientsQuery) ([]ClientInfo, error) {
+	result := []ClientInfo{}
+	for _, c := range clients {
+		if len(query.Addr) > 0 && !matchStringValueWithMask(c.Addr, query.Addr) {
+			continue
+		}
+		if len(query.Name) > 0 && !matchStringValueWithMask(c.Name, query.Name) {
+			continue
+		}
+		if len(query.Cmd) > 0 && !strings.EqualFold(c.Cmd, query.Cmd) {
+			continue
+		}
+		if len(query.User) > 0 && c.User != query.User {
+			continue
+		}
+		if query.DB != nil && c.DB != *query.DB {
+			continue
+		}
+		if !hasAllFlags(c.Flags, query.Flags) {
+			continue
+		}
+		result = append(result, c)
+	}
+
+	if len(query.SortBy) == 0 {
+		return result, nil
+	}
+
+	less, err := clientsLessFunc(result, query.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if query.Desc {
+			return less(j, i)
+		}
+		return less(i, j)
+	})
+
+	return result, nil
+}
+
+//KillClients closes connections of clients matching the filter and returns killed clients count
+func KillClients(serverName string, filter ClientKillFilter) (int64, error) {
+	args := []interface{}{"KILL"}
+	if filter.ID > 0 {
+		args = append(args, "ID", filter.ID)
+	}
+	if len(filter.Addr) > 0 {
+		args = append(args, "ADDR", filter.Addr)
+	}
+	if len(filter.User) > 0 {
+		args = append(args, "USER", filter.User)
+	}
+	if len(args) == 1 {
+		return 0, errors.New("client ID, address or user should be given")
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	r, err := conn.Do("CLIENT", args...)
+	return redis.Int64(r, err)
+}
+
+//PauseClients suspends clients for timeout milliseconds
+//mode is either ALL or WRITE, WRITE mode is supported since Redis 6.2
+func PauseClients(serverName string, timeout int64, mode string) error {
+	args := []interface{}{"PAUSE", timeout}
+	if len(mode) > 0 {
+		args = append(args, strings.ToUpper(mode))
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("CLIENT", args...)
+	return err
+}
+
+//UnpauseClients resumes clients paused with PauseClients
+func UnpauseClients(serverName string) error {
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("CLIENT", "UNPAUSE")
+	return err
+}
+
+func parseClientList(list string) []ClientInfo {
+	clients := []ClientInfo{}
+	for _, row := range strings.Split(list, "\n") {
+		row = strings.TrimSpace(row)
+		if len(row) == 0 {
+			continue
+		}
+
+		c := ClientInfo{}
+		for _, field := range strings.Split(row, " ") {
+			splittedField := strings.SplitN(field, "=", 2)
+			if len(splittedField) != 2 {
+				continue
+			}
+			name, value := splittedField[0], splittedField[1]
+			intValue, _ := strconv.ParseInt(value, 10, 64)
+			switch name {
+			case "id":
+				c.ID = intValue
+			case "addr":
+				c.Addr = value
+			case "laddr":
+				c.LAddr = value
+			case "name":
+				c.Name = value
+			case "age":
+				c.Age = intValue
+			case "idle":
+				c.Idle = intValue
+			case "db":
+				c.DB = intValue
+			case "flags":
+				c.Flags = value
+			case "cmd":
+				c.Cmd = value
+			case "user":
+				c.User = value
+			case "qbuf":
+				c.QBuf = intValue
+			case "qbuf-free":
+				c.QBufFree = intValue
+			case "obl":
+				c.OBL = intValue
+			case "oll":
+				c.OLL = intValue
+			case "omem":
+				c.OMem = intValue
+			}
+		}
+		clients = append(clients, c)
+	}
+
+	return clients
+}
+
+func hasAllFlags(clientFlags, flags string) bool {
+	for _, f := range flags {
+		if !strings.ContainsRune(clientFlags, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func clientsLessFunc(clients []ClientInfo, sortBy string) (func(i, j int) bool, error) {
+	switch strings.ToLower(sortBy) {
+	case "id":
+		return func(i, j int) bool { return clients[i].ID < clients[j].ID }, nil
+	case "addr":
+		return func(i, j int) bool { return clients[i].Addr < clients[j].Addr }, nil
+	case "name":
+		return func(i, j int) bool { return clients[i].Name < clients[j].Name }, nil
+	case "age":
+		return func(i, j int) bool { return clients[i].Age < clients[j].Age }, nil
+	case "idle":
+		return func(i, j int) bool { return clients[i].Idle < clients[j].Idle }, nil
+	case "db":
+		return func(i, j int) bool { return clients[i].DB < clients[j].DB }, nil
+	case "cmd":
+		return func(i, j int) bool { return clients[i].Cmd < clients[j].Cmd }, nil
+	case "qbuf":
+		return func(i, j int) bool { return clients[i].QBuf < clients[j].QBuf }, nil
+	case "omem":
+		return func(i, j int) bool { return clients[i].OMem < clients[j].OMem }, nil
+	}
+
+	return nil, errors.New("can't sort clients by " + sortBy)
+}
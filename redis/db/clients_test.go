@@ -0,0 +1,44 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+const clientListOutput = "id=3 addr=127.0.0.1:52555 laddr=127.0.0.1:6379 fd=8 name=worker age=120 idle=5 flags=N db=0 sub=0 psub=0 multi=-1 qbuf=26 qbuf-free=32742 obl=0 oll=0 omem=0 events=r cmd=get user=default\n" +
+	"id=5 addr=10.0.0.7:41000 laddr=127.0.0.1:6379 fd=9 name= age=30 idle=30 flags=S db=2 sub=0 psub=0 multi=-1 qbuf=0 qbuf-free=0 obl=0 oll=2 omem=4096 events=r cmd=replconf user=default\n" +
+	"id=8 addr=127.0.0.1:52600 laddr=127.0.0.1:6379 fd=10 name=cron age=600 idle=600 flags=N db=0 sub=0 psub=0 multi=-1 qbuf=0 qbuf-free=0 obl=0 oll=0 omem=0 events=r cmd=NULL user=app\n"
+
+func TestParsingClientList(t *testing.T) {
+	clients := parseClientList(clientListOutput)
+	if len(clients) != 3 {
+		t.Fatalf("got %v clients, expected 3", len(clients))
+	}
+
+	expected := ClientInfo{ID: 3, Addr: "127.0.0.1:52555", LAddr: "127.0.0.1:6379", Name: "worker", Age: 120, Idle: 5, DB: 0, Flags: "N", Cmd: "get", User: "default", QBuf: 26, QBufFree: 32742}
+	if !reflect.DeepEqual(clients[0], expected) {
+		t.Errorf("got client %+v, expected %+v", clients[0], expected)
+	}
+}
+
+func TestQueryingClients(t *testing.T) {
+	clients := parseClientList(clientListOutput)
+
+	dbNum := int64(0)
+	result, err := QueryClients(clients, ClientsQuery{Addr: "127.0.0.1:*", DB: &dbNum, SortBy: "idle", Desc: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].ID != 8 || result[1].ID != 3 {
+		t.Errorf("got invalid clients %+v", result)
+	}
+
+	result, _ = QueryClients(clients, ClientsQuery{Flags: "S"})
+	if len(result) != 1 || result[0].ID != 5 {
+		t.Errorf("got invalid clients %+v, expected only replica", result)
+	}
+
+	if _, err := QueryClients(clients, ClientsQuery{SortBy: "fd"}); err == nil {
+		t.Error("expected an error for unknown sort field")
+	}
+}
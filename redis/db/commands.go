@@ -0,0 +1,32 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/sad0vnikov/radish/config"
+)
+
+//CommandDisabledError is returned when a command is disabled on a server with rename-command
+type CommandDisabledError struct {
+	Command string
+}
+
+func (err CommandDisabledError) Error() string {
+	return "command " + err.Command + " is disabled on this server"
+}
+
+//serverCommand returns the name given command has on a server taking server's RenamedCommands into account
+func serverCommand(serverName, command string) (string, error) {
+	server := config.Get().Servers[serverName]
+	for original, renamed := range server.RenamedCommands {
+		if !strings.EqualFold(original, command) {
+			continue
+		}
+		if len(renamed) == 0 {
+			return "", CommandDisabledError{Command: strings.ToUpper(command)}
+		}
+		return renamed, nil
+	}
+
+	return command, nil
+}
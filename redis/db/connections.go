@@ -10,6 +10,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 //Connections is an interface for objects storing Redis connections
@@ -18,54 +20,107 @@ type Connections interface {
 	GetMaxDbNumsForServer(serverName string) (uint8, error)
 	GetServerKeyspaceStat(serverName string) (map[string]rd.ServerKeyspaceStat, error)
 	GetServerStat(serverName string) (rd.ServerStat, error)
+	Dial(serverName string) (redis.Conn, error)
 }
 
-//RedisConnections is a struct storing redis connection
+const defaultDatabasesCount = 16
+
+//timeouts of connections to Redis servers, a stalled server fails requests instead of blocking them forever
+const (
+	connectTimeout = 5 * time.Second
+	readTimeout    = 30 * time.Second
+	writeTimeout   = 30 * time.Second
+)
+
+func dialServer(server rd.Server) (redis.Conn, error) {
+	serverAddr := server.Host + ":" + strconv.Itoa(server.Port)
+	return redis.Dial("tcp", serverAddr,
+		redis.DialConnectTimeout(connectTimeout),
+		redis.DialReadTimeout(readTimeout),
+		redis.DialWriteTimeout(writeTimeout),
+	)
+}
+
+//RedisConnections is a struct storing pools of redis connections to every server
 type RedisConnections struct {
-	pool *redis.Pool
+	mu    sync.Mutex
+	pools map[string]*redis.Pool
 }
 
 //GetByName function returns redigo Redis connection instance by server name
-func (connections RedisConnections) GetByName(serverName string, dbNum uint8) (redis.Conn, error) {
+func (connections *RedisConnections) GetByName(serverName string, dbNum uint8) (redis.Conn, error) {
 	server, prs := config.Get().Servers[serverName]
 	if !prs {
 		return nil, errors.New("no server with name " + serverName + " found")
 	}
 
-	if connections.pool == nil {
-		connections.pool = &redis.Pool{
-			MaxIdle: 3,
+	var c redis.Conn
+	c = connections.serverPool(server).Get()
+	c.Do("SELECT", dbNum)
+
+	return c, nil
+}
+
+//serverPool returns a pool of connections to a server, pools are kept by server name and address,
+//so a server moved to another address in the config gets a new pool
+func (connections *RedisConnections) serverPool(server rd.Server) *redis.Pool {
+	serverAddr := server.Host + ":" + strconv.Itoa(server.Port)
+	poolKey := server.Name + "@" + serverAddr
+
+	connections.mu.Lock()
+	defer connections.mu.Unlock()
+
+	if connections.pools == nil {
+		connections.pools = make(map[string]*redis.Pool)
+	}
+	pool, prs := connections.pools[poolKey]
+	if !prs {
+		pool = &redis.Pool{
+			MaxIdle:     3,
+			IdleTimeout: 4 * time.Minute,
 			Dial: func() (redis.Conn, error) {
-				serverAddr := server.Host + ":" + strconv.Itoa(server.Port)
 				logger.Info("connecting to Redis server " + serverAddr)
-				conn, err := redis.Dial("tcp", serverAddr)
+				conn, err := dialServer(server)
 				logger.Info("connected to Redis server " + serverAddr)
 				return conn, err
 			},
 		}
+		connections.pools[poolKey] = pool
 	}
+	return pool
+}
 
-	var c redis.Conn
-	c = connections.pool.Get()
-	c.Do("SELECT", dbNum)
+//Dial returns a new dedicated connection to a server, which is not shared with other requests
+//the connection should be closed by the caller
+func (connections *RedisConnections) Dial(serverName string) (redis.Conn, error) {
+	server, prs := config.Get().Servers[serverName]
+	if !prs {
+		return nil, errors.New("no server with name " + serverName + " found")
+	}
 
-	return c, nil
+	return dialServer(server)
 }
 
 //GetMaxDbNumsForServer returns a maxium db number for given Redis server
-func (connections RedisConnections) GetMaxDbNumsForServer(serverName string) (uint8, error) {
-	conn, err := connections.GetByName(serverName, 0)
-	if err != nil {
-		return 0, err
+//if CONFIG command is disabled on the server, the Redis default databases count is returned
+func (connections *RedisConnections) GetMaxDbNumsForServer(serverName string) (uint8, error) {
+	databases, err := connections.getServerConfigParam(serverName, "databases")
+	if _, ok := err.(CommandDisabledError); ok {
+		conn, err := connections.GetByName(serverName, 0)
+		if err != nil {
+			return 0, err
+		}
+		_, err = conn.Do("PING")
+		if err != nil {
+			return 0, err
+		}
+		return defaultDatabasesCount, nil
 	}
-
-	r, err := conn.Do("CONFIG", "GET", "databases")
-	cfgValues, err := redis.Strings(r, err)
 	if err != nil {
 		return 0, err
 	}
 
-	cnt, err := strconv.ParseUint(cfgValues[1], 10, 8)
+	cnt, err := strconv.ParseUint(databases, 10, 8)
 
 	if err != nil {
 		return 0, err
@@ -74,7 +129,7 @@ func (connections RedisConnections) GetMaxDbNumsForServer(serverName string) (ui
 	return uint8(cnt), nil
 }
 
-func (connections RedisConnections) GetServerStat(serverName string) (rd.ServerStat, error) {
+func (connections *RedisConnections) GetServerStat(serverName string) (rd.ServerStat, error) {
 	conn, err := connections.GetByName(serverName, 0)
 	if err != nil {
 		return rd.ServerStat{}, err
@@ -87,8 +142,12 @@ func (connections RedisConnections) GetServerStat(serverName string) (rd.ServerS
 	}
 
 	c := parseInfoStrings(strings.Split(info, "\r\n"))
+	c.Replication = rd.NewReplicationStat(parseInfo(info).Replication)
 
 	maxMemory, err := connections.getServerConfigParam(serverName, "maxmemory")
+	if _, ok := err.(CommandDisabledError); ok {
+		return c, nil
+	}
 	if err != nil {
 		return rd.ServerStat{}, err
 	}
@@ -101,43 +160,35 @@ func (connections RedisConnections) GetServerStat(serverName string) (rd.ServerS
 	return c, err
 }
 
-func (connections RedisConnections) GetServerKeyspaceStat(serverName string) (map[string]rd.ServerKeyspaceStat, error) {
-	conn, err := connections.GetByName(serverName, 0)
+func (connections *RedisConnections) GetServerKeyspaceStat(serverName string) (map[string]rd.ServerKeyspaceStat, error) {
+	info, err := GetServerInfo(serverName, "keyspace")
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := conn.Do("INFO", "keyspace")
-	rs, err := redis.String(r, err)
-	info := strings.Split(rs, "\r\n")
-
-	databaseStatRowsCount := len(info) - 1
-	stat := make(map[string]rd.ServerKeyspaceStat, databaseStatRowsCount)
-	dbNum := 0
-	for i := 1; i < len(info); i++ {
-		dbName := "db" + strconv.Itoa(dbNum)
-		statString := info[i]
-
-		if len(statString) > 0 {
-			dbKeyspaceStat := parseKeyspaceStatString(statString)
-			dbNum++
-
-			stat[dbName] = dbKeyspaceStat
-		}
+	return info.Keyspace, nil
+}
 
+func (connections *RedisConnections) getServerConfigParam(serverName, paramName string) (string, error) {
+	configCommand, err := serverCommand(serverName, "CONFIG")
+	if err != nil {
+		return "", err
 	}
-	return stat, nil
-}
 
-func (connections RedisConnections) getServerConfigParam(serverName, paramName string)  (string, error) {
 	conn, err := connections.GetByName(serverName, 0)
 	if err != nil {
 		return "", err
 	}
 
-	r, err := conn.Do("CONFIG", "GET", paramName)
+	r, err := conn.Do(configCommand, "GET", paramName)
 	rs, err := redis.Strings(r, err)
-	return rs[1], err
+	if err != nil {
+		return "", err
+	}
+	if len(rs) < 2 {
+		return "", errors.New("config param " + paramName + " not found")
+	}
+	return rs[1], nil
 }
 
 func parseKeyspaceStatString(statString string) rd.ServerKeyspaceStat {
@@ -153,6 +204,12 @@ func parseKeyspaceStatString(statString string) rd.ServerKeyspaceStat {
 		case "keys":
 			intValue, _ := strconv.ParseInt(statValue, 10, 64)
 			dbKeyspaceStat.KeysCount = intValue
+		case "expires":
+			intValue, _ := strconv.ParseInt(statValue, 10, 64)
+			dbKeyspaceStat.Expires = intValue
+		case "avg_ttl":
+			intValue, _ := strconv.ParseInt(statValue, 10, 64)
+			dbKeyspaceStat.AvgTTL = intValue
 		}
 	}
 	return dbKeyspaceStat
@@ -207,6 +264,11 @@ func (connections MockedConnections) GetMaxDbNumsForServer(serverName string) (u
 	return uint8(8), nil
 }
 
+//Dial returns mocked connection
+func (connections MockedConnections) Dial(serverName string) (redis.Conn, error) {
+	return connections.GetByName(serverName, 0)
+}
+
 func (connections MockedConnections) GetServerStat(serverName string) (rd.ServerStat, error) {
 	return rd.ServerStat{}, nil
 }
@@ -216,10 +278,15 @@ func (connections MockedConnections) GetServerKeyspaceStat(serverName string) (m
 }
 
 //GetServersWithConnectionData returns servers list from config with connection data like databases count, connection status, etc.
+//commands policies and trash settings are left out, they may contain secret names of renamed commands
 func GetServersWithConnectionData() map[string]rd.Server {
 	configServers := config.Get().Servers
 	result := make(map[string]rd.Server)
 	for _, srv := range configServers {
+		srv.RenamedCommands = nil
+		srv.AllowedCommands = nil
+		srv.DeniedCommands = nil
+		srv.Trash = nil
 		dbsCount, err := GetMaxDbNumsForServer(srv.Name)
 		if err == nil {
 			srv.ConnectionCheckPassed = true
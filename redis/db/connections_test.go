@@ -16,3 +16,26 @@ func TestCorrectStringParses(t *testing.T) {
 		t.Errorf("got wrong parsed string '%s': %v, expected: %v", s, result, expected)
 	}
 }
+
+func TestKeyspaceStatStringWithExpiresParses(t *testing.T) {
+	s := "db1:keys=12,expires=3,avg_ttl=1500"
+	expected := redis.ServerKeyspaceStat{KeysCount: 12, Expires: 3, AvgTTL: 1500}
+
+	result := parseKeyspaceStatString(s)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got wrong parsed string '%s': %v, expected: %v", s, result, expected)
+	}
+}
+
+func TestReusingServerConnectionPools(t *testing.T) {
+	connections := &RedisConnections{}
+	server := redis.NewServer("server1", "127.0.0.1", 6379)
+
+	pool := connections.serverPool(server)
+	if connections.serverPool(server) != pool {
+		t.Errorf("expected connections to a server to share a pool")
+	}
+	if connections.serverPool(redis.NewServer("server1", "127.0.0.1", 6380)) == pool {
+		t.Errorf("expected a server moved to another address to get a new pool")
+	}
+}
@@ -0,0 +1,272 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/sad0vnikov/radish/config"
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+const (
+	//ReplyStatus is a simple string reply type, e.g. OK
+	ReplyStatus = "status"
+	//ReplyString is a bulk string reply type
+	ReplyString = "string"
+	//ReplyInteger is an integer reply type
+	ReplyInteger = "integer"
+	//ReplyNil is a nil reply type
+	ReplyNil = "nil"
+	//ReplyError is an error reply type
+	ReplyError = "error"
+	//ReplyArray is an array reply type
+	ReplyArray = "array"
+)
+
+//CommandReply is a JSON-friendly representation of Redis reply
+//Value is a []CommandReply for arrays
+type CommandReply struct {
+	Type     string
+	Value    interface{}
+	IsBinary bool `json:",omitempty"`
+}
+
+//CommandNotAllowedError is returned when a command is forbidden by server commands policy
+type CommandNotAllowedError struct {
+	Command string
+}
+
+func (err CommandNotAllowedError) Error() string {
+	return "command " + err.Command + " is not allowed on this server"
+}
+
+//blockedConsoleCommands take over the connection and can't be run from the console
+var blockedConsoleCommands = map[string]bool{
+	"MONITOR":    true,
+	"SUBSCRIBE":  true,
+	"PSUBSCRIBE": true,
+	"SSUBSCRIBE": true,
+	"SYNC":       true,
+	"PSYNC":      true,
+}
+
+//adminCommands change server state or wipe data, they are refused on servers which don't allow administrative operations
+//Lua scripts are refused as well since they can call any of these commands
+var adminCommands = map[string]bool{
+	"ACL":          true,
+	"BGREWRITEAOF": true,
+	"BGSAVE":       true,
+	"CLIENT":       true,
+	"CLUSTER":      true,
+	"CONFIG":       true,
+	"DEBUG":        true,
+	"EVAL":         true,
+	"EVALSHA":      true,
+	"EVALSHA_RO":   true,
+	"EVAL_RO":      true,
+	"FAILOVER":     true,
+	"FLUSHALL":     true,
+	"FLUSHDB":      true,
+	"FUNCTION":     true,
+	"MIGRATE":      true,
+	"MODULE":       true,
+	"REPLICAOF":    true,
+	"SAVE":         true,
+	"SCRIPT":       true,
+	"SHUTDOWN":     true,
+	"SLAVEOF":      true,
+	"SWAPDB":       true,
+}
+
+//CheckCommandAllowed returns CommandNotAllowedError if server's AllowedCommands and DeniedCommands or server mode forbid a command
+func CheckCommandAllowed(serverName, command string) error {
+	command = strings.ToUpper(command)
+	if blockedConsoleCommands[command] {
+		return CommandNotAllowedError{Command: command}
+	}
+
+	server := config.Get().Servers[serverName]
+	if adminCommands[command] && !server.Allows(rd.AccessAdmin) {
+		return CommandNotAllowedError{Command: command}
+	}
+	for _, denied := range server.DeniedCommands {
+		if strings.EqualFold(denied, command) {
+			return CommandNotAllowedError{Command: command}
+		}
+	}
+
+	if len(server.AllowedCommands) == 0 {
+		return nil
+	}
+	for _, allowed := range server.AllowedCommands {
+		if strings.EqualFold(allowed, command) {
+			return nil
+		}
+	}
+
+	return CommandNotAllowedError{Command: command}
+}
+
+//ExecuteCommand runs a raw command if it's allowed by server commands policy
+//Redis errors are returned as a reply of error type, not as an error
+func ExecuteCommand(serverName string, dbNum uint8, args []string) (CommandReply, error) {
+	if len(args) == 0 {
+		return CommandReply{}, errors.New("command is empty")
+	}
+
+	err := CheckCommandAllowed(serverName, args[0])
+	if err != nil {
+		return CommandReply{}, err
+	}
+
+	command, err := serverCommand(serverName, args[0])
+	if err != nil {
+		return CommandReply{}, err
+	}
+
+	conn, err := connector.GetByName(serverName, dbNum)
+	if err != nil {
+		return CommandReply{}, err
+	}
+	defer conn.Close()
+
+	commandArgs := make([]interface{}, len(args)-1)
+	for i, arg := range args[1:] {
+		commandArgs[i] = arg
+	}
+
+	return NewCommandReply(conn.Do(command, commandArgs...))
+}
+
+//NewCommandReply converts a redigo reply to CommandReply
+func NewCommandReply(r interface{}, err error) (CommandReply, error) {
+	if rerr, ok := err.(redis.Error); ok {
+		return CommandReply{Type: ReplyError, Value: rerr.Error()}, nil
+	}
+	if err != nil {
+		return CommandReply{}, err
+	}
+
+	switch v := r.(type) {
+	case nil:
+		return CommandReply{Type: ReplyNil}, nil
+	case int64:
+		return CommandReply{Type: ReplyInteger, Value: v}, nil
+	case string:
+		return CommandReply{Type: ReplyStatus, Value: v}, nil
+	case []byte:
+		s := string(v)
+		return CommandReply{Type: ReplyString, Value: s, IsBinary: isBinary(s)}, nil
+	case redis.Error:
+		return CommandReply{Type: ReplyError, Value: v.Error()}, nil
+	case []interface{}:
+		elements := make([]CommandReply, len(v))
+		for i, e := range v {
+			elements[i], err = NewCommandReply(e, nil)
+			if err != nil {
+				return CommandReply{}, err
+			}
+		}
+		return CommandReply{Type: ReplyArray, Value: elements}, nil
+	}
+
+	return CommandReply{}, errors.New("got unexpected reply type")
+}
+
+//ParseCommandLine splits a command line into arguments the way redis-cli does
+//arguments can be quoted with double quotes supporting escape sequences or with single quotes
+func ParseCommandLine(line string) ([]string, error) {
+	args := []string{}
+	i := 0
+	for i < len(line) {
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i == len(line) {
+			break
+		}
+
+		arg := []byte{}
+		switch line[i] {
+		case '"':
+			quoted, err := parseQuotedArgs(readQuoted(line, &i, '"'))
+			if err != nil || len(quoted) != 1 {
+				return nil, errors.New("unbalanced quotes in command line")
+			}
+			arg = []byte(quoted[0])
+		case '\'':
+			quoted := readQuoted(line, &i, '\'')
+			if len(quoted) < 2 {
+				return nil, errors.New("unbalanced quotes in command line")
+			}
+			arg = []byte(strings.Replace(quoted[1:len(quoted)-1], `\'`, `'`, -1))
+		default:
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				arg = append(arg, line[i])
+				i++
+			}
+		}
+		if i < len(line) && line[i] != ' ' && line[i] != '\t' {
+			return nil, errors.New("closing quote must be followed by a space")
+		}
+
+		args = append(args, string(arg))
+	}
+
+	return args, nil
+}
+
+//FormatCommandLine joins arguments into a command line, arguments with spaces, quotes or special characters are quoted
+func FormatCommandLine(args []string) string {
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		formatted[i] = quoteArg(arg)
+	}
+	return strings.Join(formatted, " ")
+}
+
+func quoteArg(arg string) string {
+	if len(arg) > 0 && !strings.ContainsAny(arg, " \t\r\n\"'\\") && !isBinary(arg) {
+		return arg
+	}
+
+	quoted := []byte{'"'}
+	for i := 0; i < len(arg); i++ {
+		switch c := arg[i]; {
+		case c == '"' || c == '\\':
+			quoted = append(quoted, '\\', c)
+		case c == '\n':
+			quoted = append(quoted, '\\', 'n')
+		case c == '\r':
+			quoted = append(quoted, '\\', 'r')
+		case c == '\t':
+			quoted = append(quoted, '\\', 't')
+		case c < 0x20 || c > 0x7e:
+			quoted = append(quoted, []byte(fmt.Sprintf("\\x%02x", c))...)
+		default:
+			quoted = append(quoted, c)
+		}
+	}
+	return string(append(quoted, '"'))
+}
+
+//readQuoted returns a quoted part of line starting at *i including the quotes and moves *i after it
+//an empty string is returned if the closing quote is missing
+func readQuoted(line string, i *int, quote byte) string {
+	start := *i
+	for j := start + 1; j < len(line); j++ {
+		if line[j] == '\\' {
+			j++
+			continue
+		}
+		if line[j] == quote {
+			*i = j + 1
+			return line[start:*i]
+		}
+	}
+
+	*i = len(line)
+	return ""
+}
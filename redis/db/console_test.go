@@ -0,0 +1,92 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+func TestParsingCommandLine(t *testing.T) {
+	args, err := ParseCommandLine(`set  "my key" 'it\'s' "a\nb\x41"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"set", "my key", "it's", "a\nbA"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("got args %q, expected %q", args, expected)
+	}
+
+	if _, err := ParseCommandLine(`get "key`); err == nil {
+		t.Error("expected an error for unbalanced quotes")
+	}
+
+	formatted := FormatCommandLine(expected)
+	if formatted != `set "my key" "it's" "a\nbA"` {
+		t.Errorf("got formatted command line %v", formatted)
+	}
+}
+
+func TestCheckingCommandsPolicy(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	servers := config.Get().Servers
+	srv := servers["server1"]
+	srv.AllowedCommands = []string{"get", "set", "del"}
+	srv.DeniedCommands = []string{"DEL"}
+	servers["server1"] = srv
+	defer config.StubConfigLoader{}.Load()
+
+	if err := CheckCommandAllowed("server1", "GET"); err != nil {
+		t.Errorf("expected GET to be allowed, got %v", err)
+	}
+	for _, command := range []string{"del", "flushall", "monitor"} {
+		if _, ok := CheckCommandAllowed("server1", command).(CommandNotAllowedError); !ok {
+			t.Errorf("expected %v to be forbidden", command)
+		}
+	}
+}
+
+func TestCheckingCommandsInProtectedMode(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	servers := config.Get().Servers
+	srv := servers["server1"]
+	srv.Mode = rd.ModeProtected
+	servers["server1"] = srv
+	defer config.StubConfigLoader{}.Load()
+
+	if err := CheckCommandAllowed("server1", "set"); err != nil {
+		t.Errorf("expected SET to be allowed, got %v", err)
+	}
+	if _, ok := CheckCommandAllowed("server1", "flushall").(CommandNotAllowedError); !ok {
+		t.Error("expected FLUSHALL to be forbidden on a protected server")
+	}
+}
+
+func TestExecutingCommand(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("HMGET", "hash", "a", "b").Expect([]interface{}{[]byte("1"), nil})
+	conn.Command("LPUSH", "hash", "a").ExpectError(redis.Error("WRONGTYPE Operation against a key holding the wrong kind of value"))
+
+	reply, err := ExecuteCommand("server1", 0, []string{"HMGET", "hash", "a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := CommandReply{Type: ReplyArray, Value: []CommandReply{{Type: ReplyString, Value: "1"}, {Type: ReplyNil}}}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Errorf("got reply %+v, expected %+v", reply, expected)
+	}
+
+	reply, err = ExecuteCommand("server1", 0, []string{"LPUSH", "hash", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Type != ReplyError {
+		t.Errorf("got reply %+v, expected an error reply", reply)
+	}
+}
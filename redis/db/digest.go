@@ -0,0 +1,122 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+//maxDigestMemoryUsage is a size in bytes of keys in memory up to which their values are serialized to compute a digest
+const maxDigestMemoryUsage = 1024 * 1024
+
+//KeyDigest describes a key value at some moment
+//SHA256 is a digest of the value serialized with DUMP and Size is the serialized value size,
+//Value is the value itself, it's set only for values not bigger than the size limit
+//keys taking more than maxDigestMemoryUsage in memory are not serialized, their Size is the memory usage and SHA256 is empty
+type KeyDigest struct {
+	Exists bool
+	Type   string      `json:",omitempty"`
+	Size   int         `json:",omitempty"`
+	SHA256 string      `json:",omitempty"`
+	Value  interface{} `json:",omitempty"`
+}
+
+//GetKeyDigest returns a digest of a key value, the value is included if its serialized size is not bigger than maxValueSize
+func GetKeyDigest(serverName string, dbNum uint8, key string, maxValueSize int) (KeyDigest, error) {
+	conn, err := connector.GetByName(serverName, dbNum)
+	if err != nil {
+		return KeyDigest{}, err
+	}
+	defer conn.Close()
+
+	k, err := dumpKey(conn, serverName, key, maxDigestMemoryUsage)
+	if err != nil || !k.exists {
+		return KeyDigest{}, err
+	}
+
+	digest := KeyDigest{Exists: true, Type: k.keyType, Size: k.size}
+	if k.dump == nil {
+		return digest, nil
+	}
+	digest.SHA256 = dumpSHA256(k.dump)
+	if k.size > maxValueSize {
+		return digest, nil
+	}
+
+	switch k.keyType {
+	case RedisString:
+		digest.Value, err = redis.String(conn.Do("GET", key))
+	case RedisList:
+		digest.Value, err = redis.Strings(conn.Do("LRANGE", key, 0, -1))
+	case RedisHash:
+		digest.Value, err = redis.StringMap(conn.Do("HGETALL", key))
+	case RedisSet:
+		digest.Value, err = redis.Strings(conn.Do("SMEMBERS", key))
+	case RedisZset:
+		digest.Value, err = redis.Strings(conn.Do("ZRANGE", key, 0, -1, "WITHSCORES"))
+	}
+	if err != nil {
+		return KeyDigest{}, err
+	}
+
+	return digest, nil
+}
+
+//dumpedKey is a key value serialized with DUMP, dump is nil for keys which were too big to be serialized
+//and size is their memory usage then
+type dumpedKey struct {
+	exists  bool
+	keyType string
+	size    int
+	dump    []byte
+}
+
+//dumpKey serializes a key value with DUMP unless the key takes more than maxMemoryUsage bytes in memory
+//keys are serialized regardless of their size if the server can't tell it, e.g. Redis before 4.0 doesn't support MEMORY USAGE
+func dumpKey(conn redis.Conn, serverName, key string, maxMemoryUsage int64) (dumpedKey, error) {
+	dumpCommand, err := serverCommand(serverName, "DUMP")
+	if err != nil {
+		return dumpedKey{}, err
+	}
+	typeCommand, err := serverCommand(serverName, "TYPE")
+	if err != nil {
+		return dumpedKey{}, err
+	}
+
+	var k dumpedKey
+	usage, err := keyMemoryUsage(conn, serverName, key)
+	if err == redis.ErrNil {
+		return k, nil
+	}
+	if err == nil && usage > maxMemoryUsage {
+		k.size = int(usage)
+	} else {
+		k.dump, err = redis.Bytes(conn.Do(dumpCommand, key))
+		if err == redis.ErrNil {
+			return k, nil
+		}
+		if err != nil {
+			return k, err
+		}
+		k.size = len(k.dump)
+	}
+
+	k.exists = true
+	k.keyType, err = redis.String(conn.Do(typeCommand, key))
+	return k, err
+}
+
+//keyMemoryUsage returns a number of bytes a key takes in memory, redis.ErrNil is returned if the key doesn't exist
+func keyMemoryUsage(conn redis.Conn, serverName, key string) (int64, error) {
+	memoryCommand, err := serverCommand(serverName, "MEMORY")
+	if err != nil {
+		return 0, err
+	}
+	return redis.Int64(conn.Do(memoryCommand, "USAGE", key))
+}
+
+func dumpSHA256(dump []byte) string {
+	sum := sha256.Sum256(dump)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+)
+
+func TestGettingKeyDigest(t *testing.T) {
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("DUMP", "session:1").Expect([]byte("dumped"))
+	conn.Command("TYPE", "session:1").Expect("hash")
+	conn.Command("HGETALL", "session:1").Expect([]interface{}{[]byte("user"), []byte("alice")})
+	conn.Command("DUMP", "missing").Expect(nil)
+
+	digest, err := GetKeyDigest("server1", 0, "session:1", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := KeyDigest{
+		Exists: true,
+		Type:   "hash",
+		Size:   6,
+		//SHA-256 of 'dumped'
+		SHA256: "31c4c1c4bd0e7d6376b0648925a30859cd8c281ef7a6d89b79b9a38ddac77e02",
+		Value:  map[string]string{"user": "alice"},
+	}
+	if !reflect.DeepEqual(digest, expected) {
+		t.Errorf("got digest %+v, expected %+v", digest, expected)
+	}
+
+	digest, err = GetKeyDigest("server1", 0, "session:1", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest.Value != nil || digest.SHA256 != expected.SHA256 {
+		t.Errorf("expected only a digest of a big value, got %+v", digest)
+	}
+
+	digest, err = GetKeyDigest("server1", 0, "missing", 100)
+	if err != nil || digest.Exists {
+		t.Errorf("got digest %+v and error %v for a missing key", digest, err)
+	}
+}
+
+func TestNotSerializingBigKeyForDigest(t *testing.T) {
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("MEMORY", "USAGE", "events").Expect(int64(maxDigestMemoryUsage + 1))
+	dump := conn.Command("DUMP", "events").Expect([]byte("dumped"))
+	conn.Command("TYPE", "events").Expect("list")
+
+	digest, err := GetKeyDigest("server1", 0, "events", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := KeyDigest{Exists: true, Type: "list", Size: maxDigestMemoryUsage + 1}
+	if !reflect.DeepEqual(digest, expected) || conn.Stats(dump) != 0 {
+		t.Errorf("got digest %+v, expected %+v without serializing the key", digest, expected)
+	}
+}
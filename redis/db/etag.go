@@ -0,0 +1,132 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+//ETagMismatchError is returned when a key was changed since a client got its ETag
+type ETagMismatchError struct {
+	Key string
+}
+
+func (err ETagMismatchError) Error() string {
+	return "key " + err.Key + " was changed by someone else, reload its value and try again"
+}
+
+//KeyETag returns an entity tag of a key value, it's a quoted SHA-256 digest of the value serialized with DUMP
+//an empty string is returned if the key doesn't exist or takes more memory than the snapshot size limit,
+//such keys are not serialized and can be changed conditionally only with If-Match: *
+func KeyETag(serverName string, dbNum uint8, key string) (string, error) {
+	conn, err := connector.GetByName(serverName, dbNum)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	k, err := dumpSnapshotKey(conn, serverName, key)
+	if err != nil {
+		return "", err
+	}
+	return dumpETag(k), nil
+}
+
+//dumpETag returns an ETag of a serialized key, an empty string is returned if the key wasn't serialized
+func dumpETag(k dumpedKey) string {
+	if k.dump == nil {
+		return ""
+	}
+	return `"` + dumpSHA256(k.dump) + `"`
+}
+
+//etagMatches checks a key against If-Match header value, which is either a list of ETags or * matching any existing key
+func etagMatches(k dumpedKey, ifMatch string) bool {
+	if !k.exists {
+		return false
+	}
+
+	etag := dumpETag(k)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || len(etag) > 0 && tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+//updateKey changes a key with commands returned by fn, the commands are executed on a single connection within MULTI/EXEC
+//if ifMatch is not empty, the key is watched with WATCH before its ETag is compared with ifMatch,
+//so the commands are executed only if nobody changed the key since the client got the ETag, ETagMismatchError is returned otherwise
+//fn may read the key with conn.Do before the transaction is started, the key state is captured before fn is called
+//and saved as a snapshot once the commands are executed
+//the key is serialized once for both the ETag and the snapshot
+func updateKey(serverName string, dbNum uint8, key, operation, ifMatch string, fn func(conn redis.Conn) ([]command, error)) error {
+	return updateWatchedKey(serverName, dbNum, key, operation, ifMatch, false, fn)
+}
+
+//updateWatchedKey changes a key like updateKey, the key is watched with WATCH even without ifMatch if watch is true,
+//so commands fn returns after checking the key state are not executed if someone changes the key meanwhile
+func updateWatchedKey(serverName string, dbNum uint8, key, operation, ifMatch string, watch bool, fn func(conn redis.Conn) ([]command, error)) error {
+	conn, err := connector.GetByName(serverName, dbNum)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	watched := watch || len(ifMatch) > 0
+	s, commands, err := prepareKeyUpdate(conn, serverName, dbNum, key, operation, ifMatch, watched, fn)
+	if err != nil {
+		if watched {
+			conn.Do("UNWATCH")
+		}
+		return err
+	}
+
+	//a single command is atomic by itself
+	if !watched && len(commands) == 1 {
+		_, err = conn.Do(commands[0].name, commands[0].args...)
+		if err == nil {
+			snapshots.add(s)
+		}
+		return err
+	}
+
+	replies, err := execTransaction(conn, commands)
+	if _, ok := err.(TransactionAbortedError); ok {
+		return ETagMismatchError{Key: key}
+	}
+	//Redis doesn't roll back a transaction, so the key is changed if EXEC replied even if some commands failed
+	if replies != nil {
+		snapshots.add(s)
+	}
+	return err
+}
+
+//prepareKeyUpdate checks the key ETag and returns its snapshot and commands changing it,
+//the snapshot is added to the history by the caller only after the commands are executed
+func prepareKeyUpdate(conn redis.Conn, serverName string, dbNum uint8, key, operation, ifMatch string, watched bool, fn func(conn redis.Conn) ([]command, error)) (Snapshot, []command, error) {
+	if watched {
+		_, err := conn.Do("WATCH", key)
+		if err != nil {
+			return Snapshot{}, nil, err
+		}
+	}
+
+	k, err := dumpSnapshotKey(conn, serverName, key)
+	if err != nil {
+		return Snapshot{}, nil, err
+	}
+	if len(ifMatch) > 0 && !etagMatches(k, ifMatch) {
+		return Snapshot{}, nil, ETagMismatchError{Key: key}
+	}
+
+	s, err := newSnapshot(conn, serverName, dbNum, key, operation, k)
+	if err != nil {
+		return Snapshot{}, nil, err
+	}
+
+	commands, err := fn(conn)
+	return s, commands, err
+}
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+)
+
+//ETag of a key dumped as 'dumped'
+const dumpedETag = `"31c4c1c4bd0e7d6376b0648925a30859cd8c281ef7a6d89b79b9a38ddac77e02"`
+
+func TestGettingKeyETag(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("DUMP", "user:1").Expect([]byte("dumped"))
+	conn.Command("TYPE", "user:1").Expect("hash")
+	conn.Command("DUMP", "missing").Expect(nil)
+
+	etag, err := KeyETag("server1", 0, "user:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag != dumpedETag {
+		t.Errorf("got ETag %v, expected %v", etag, dumpedETag)
+	}
+
+	etag, err = KeyETag("server1", 0, "missing")
+	if err != nil || etag != "" {
+		t.Errorf("got ETag %v and error %v for missing key, expected an empty ETag", etag, err)
+	}
+
+	conn.Command("MEMORY", "USAGE", "events").Expect(int64(config.Get().Snapshots.MaxValueSize + 1))
+	dump := conn.Command("DUMP", "events").Expect([]byte("dumped"))
+	conn.Command("TYPE", "events").Expect("list")
+	etag, err = KeyETag("server1", 0, "events")
+	if err != nil || etag != "" || conn.Stats(dump) != 0 {
+		t.Errorf("got ETag %v and error %v for a key bigger than the snapshot limit, expected no ETag without serializing the key", etag, err)
+	}
+}
+
+func TestMatchingETags(t *testing.T) {
+	dumped := dumpedKey{exists: true, keyType: "string", size: 6, dump: []byte("dumped")}
+	tooBig := dumpedKey{exists: true, keyType: "string", size: 1 << 30}
+	cases := []struct {
+		key     dumpedKey
+		ifMatch string
+		matches bool
+	}{
+		{dumped, dumpedETag, true},
+		{dumped, `"b", ` + dumpedETag, true},
+		{dumped, `"b"`, false},
+		{dumped, `W/` + dumpedETag, false},
+		{dumped, `*`, true},
+		{tooBig, `*`, true},
+		{tooBig, `""`, false},
+		{dumpedKey{}, `*`, false},
+		{dumpedKey{}, `""`, false},
+	}
+
+	for _, c := range cases {
+		if etagMatches(c.key, c.ifMatch) != c.matches {
+			t.Errorf("expected key %+v matching If-Match %v to be %v", c.key, c.ifMatch, c.matches)
+		}
+	}
+}
+
+func TestUpdatingHashKeyIfETagMatches(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	watch := conn.Command("WATCH", "user:1").Expect("OK")
+	dump := conn.Command("DUMP", "user:1").Expect([]byte("dumped"))
+	conn.Command("TYPE", "user:1").Expect("hash")
+	conn.Command("PTTL", "user:1").Expect(int64(-1))
+	conn.Command("HEXISTS", "user:1", "login").Expect(int64(0))
+	conn.Command("MULTI").Expect("OK")
+	hdel := conn.Command("HDEL", "user:1", "name").Expect("QUEUED")
+	hset := conn.Command("HSET", "user:1", "login", "alice").Expect("QUEUED")
+	exec := conn.Command("EXEC").Expect([]interface{}{int64(1), int64(1)})
+
+	err := UpdateHashKey("server1", 0, "user:1", "name", "login", "alice", dumpedETag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Stats(watch) != 1 || conn.Stats(hdel) != 1 || conn.Stats(hset) != 1 || conn.Stats(exec) != 1 {
+		t.Errorf("expected the hash key to be renamed in a transaction on a watched key")
+	}
+	if conn.Stats(dump) != 1 {
+		t.Errorf("got %v DUMP calls, expected the key to be serialized once for its ETag and snapshot", conn.Stats(dump))
+	}
+	if len(GetSnapshots("server1", nil, "user:1")) != 1 {
+		t.Errorf("expected a snapshot of the updated key")
+	}
+}
+
+func TestNotUpdatingKeyIfETagDoesntMatch(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("WATCH", "user:1").Expect("OK")
+	conn.Command("DUMP", "user:1").Expect([]byte("changed"))
+	conn.Command("TYPE", "user:1").Expect("string")
+	unwatch := conn.Command("UNWATCH").Expect("OK")
+	set := conn.Command("SET", "user:1", "alice").Expect("OK")
+
+	err := Set("server1", 0, "user:1", "alice", dumpedETag)
+	if _, ok := err.(ETagMismatchError); !ok {
+		t.Fatalf("got error %v, expected ETagMismatchError", err)
+	}
+	if conn.Stats(set) != 0 {
+		t.Errorf("expected the key not to be changed")
+	}
+	if conn.Stats(unwatch) != 1 {
+		t.Errorf("expected the key to be unwatched")
+	}
+	if len(GetSnapshots("server1", nil, "user:1")) != 0 {
+		t.Errorf("expected no snapshots to be taken")
+	}
+}
+
+func TestFailingUpdateIfKeyIsChangedBeforeExec(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("WATCH", "tags").Expect("OK")
+	conn.Command("DUMP", "tags").Expect([]byte("dumped"))
+	conn.Command("TYPE", "tags").Expect("set")
+	conn.Command("PTTL", "tags").Expect(int64(-1))
+	conn.Command("MULTI").Expect("OK")
+	conn.Command("SREM", "tags", "old").Expect("QUEUED")
+	conn.Command("SADD", "tags", "new").Expect("QUEUED")
+	conn.Command("EXEC").Expect(nil)
+
+	err := UpdateSetValue("server1", 0, "tags", "old", "new", "*")
+	if _, ok := err.(ETagMismatchError); !ok {
+		t.Errorf("got error %v, expected ETagMismatchError", err)
+	}
+	if len(GetSnapshots("server1", nil, "tags")) != 0 {
+		t.Errorf("expected no snapshots of an aborted update")
+	}
+}
+
+func TestWatchingRenamedHashKeyWithoutETag(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	watch := conn.Command("WATCH", "user:1").Expect("OK")
+	conn.Command("DUMP", "user:1").Expect([]byte("dumped"))
+	conn.Command("TYPE", "user:1").Expect("hash")
+	conn.Command("PTTL", "user:1").Expect(int64(-1))
+	conn.Command("HEXISTS", "user:1", "login").Expect(int64(0))
+	conn.Command("MULTI").Expect("OK")
+	conn.Command("HDEL", "user:1", "name").Expect("QUEUED")
+	conn.Command("HSET", "user:1", "login", "alice").Expect("QUEUED")
+	conn.Command("EXEC").Expect(nil)
+
+	err := UpdateHashKey("server1", 0, "user:1", "name", "login", "alice", "")
+	if _, ok := err.(ETagMismatchError); !ok {
+		t.Errorf("got error %v, expected ETagMismatchError when the new hash key is set concurrently", err)
+	}
+	if conn.Stats(watch) != 1 {
+		t.Errorf("expected a renamed hash to be watched without If-Match")
+	}
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"github.com/sad0vnikov/radish/config"
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+//ServerModeError is returned when an operation is not allowed by server mode
+type ServerModeError struct {
+	Server string
+	Mode   string
+}
+
+func (err ServerModeError) Error() string {
+	return "server " + err.Server + " is in " + err.Mode + " mode, the operation is not allowed"
+}
+
+//CheckServerMode returns ServerModeError if server mode doesn't permit operations of a given access level
+func CheckServerMode(serverName string, level rd.AccessLevel) error {
+	server := config.Get().Servers[serverName]
+	if !server.Allows(level) {
+		return ServerModeError{Server: serverName, Mode: server.Mode}
+	}
+	return nil
+}
+
+//GetServerKeyspaceStat returns keys count of every not empty server database
+func GetServerKeyspaceStat(serverName string) (map[string]rd.ServerKeyspaceStat, error) {
+	return connector.GetServerKeyspaceStat(serverName)
+}
+
+//FlushDB removes all keys of a database, the keys are freed in background if async is true
+func FlushDB(serverName string, dbNum uint8, async bool) error {
+	return flush(serverName, dbNum, "FLUSHDB", async)
+}
+
+//FlushAll removes all keys of all databases, the keys are freed in background if async is true
+func FlushAll(serverName string, async bool) error {
+	return flush(serverName, 0, "FLUSHALL", async)
+}
+
+func flush(serverName string, dbNum uint8, command string, async bool) error {
+	err := CheckServerMode(serverName, rd.AccessAdmin)
+	if err != nil {
+		return err
+	}
+
+	command, err = serverCommand(serverName, command)
+	if err != nil {
+		return err
+	}
+
+	conn, err := connector.GetByName(serverName, dbNum)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	args := []interface{}{}
+	if async {
+		args = append(args, "ASYNC")
+	}
+
+	_, err = conn.Do(command, args...)
+	return err
+}
+
+//SwapDB swaps two databases
+func SwapDB(serverName string, first, second uint8) error {
+	err := CheckServerMode(serverName, rd.AccessAdmin)
+	if err != nil {
+		return err
+	}
+
+	command, err := serverCommand(serverName, "SWAPDB")
+	if err != nil {
+		return err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do(command, first, second)
+	return err
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+func TestFlushingProtectedServer(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	servers := config.Get().Servers
+	srv := servers["server1"]
+	srv.Mode = rd.ModeProtected
+	servers["server1"] = srv
+	defer config.StubConfigLoader{}.Load()
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	flushCmd := conn.Command("FLUSHALL", "ASYNC").Expect("OK")
+
+	err := FlushAll("server1", true)
+	if _, ok := err.(ServerModeError); !ok {
+		t.Errorf("got error %v, expected ServerModeError", err)
+	}
+	if conn.Stats(flushCmd) != 0 {
+		t.Error("FLUSHALL shouldn't be sent to a protected server")
+	}
+
+	err = FlushAll("server2", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Stats(flushCmd) != 1 {
+		t.Error("FLUSHALL ASYNC wasn't sent")
+	}
+}
@@ -0,0 +1,270 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+//FunctionLibrary is a Redis Functions library
+type FunctionLibrary struct {
+	Name      string
+	Engine    string
+	Functions []Function
+	Code      string `json:",omitempty"`
+}
+
+//Function is a function registered by a library
+type Function struct {
+	Name        string
+	Description string
+	Flags       []string
+}
+
+//FunctionsNotSupportedError is returned for servers older than Redis 7
+type FunctionsNotSupportedError struct {
+	Version string
+}
+
+func (err FunctionsNotSupportedError) Error() string {
+	return "functions require Redis 7.0 or newer, the server runs Redis " + err.Version
+}
+
+//UnknownRestorePolicyError is returned for FUNCTION RESTORE policies Redis doesn't support
+type UnknownRestorePolicyError struct {
+	Policy string
+}
+
+func (err UnknownRestorePolicyError) Error() string {
+	return "unknown restore policy " + err.Policy + ", expected APPEND, REPLACE or FLUSH"
+}
+
+//functionsRestorePolicies are the policies FUNCTION RESTORE supports
+var functionsRestorePolicies = map[string]bool{
+	"APPEND":  true,
+	"REPLACE": true,
+	"FLUSH":   true,
+}
+
+func checkFunctionsSupported(serverName string) error {
+	info, err := GetServerInfo(serverName, "server")
+	if err != nil {
+		return err
+	}
+
+	version := info.Server.RedisVersion
+	major, err := strconv.Atoi(strings.Split(version, ".")[0])
+	if err != nil || major < 7 {
+		return FunctionsNotSupportedError{Version: version}
+	}
+	return nil
+}
+
+//functionCommand checks that a server supports functions and returns FUNCTION command name on it
+func functionCommand(serverName string) (string, error) {
+	err := checkFunctionsSupported(serverName)
+	if err != nil {
+		return "", err
+	}
+
+	return serverCommand(serverName, "FUNCTION")
+}
+
+//functionChangeCommand returns FUNCTION command name on a server for changing libraries
+//CommandNotAllowedError is returned if server commands policy or mode forbid FUNCTION
+func functionChangeCommand(serverName string) (string, error) {
+	err := CheckCommandAllowed(serverName, "FUNCTION")
+	if err != nil {
+		return "", err
+	}
+
+	return functionCommand(serverName)
+}
+
+//GetFunctions returns libraries with names matching the pattern, library code is returned if withCode is true
+func GetFunctions(serverName, pattern string, withCode bool) ([]FunctionLibrary, error) {
+	command, err := functionCommand(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	args := []interface{}{"LIST"}
+	if len(pattern) > 0 {
+		args = append(args, "LIBRARYNAME", pattern)
+	}
+	if withCode {
+		args = append(args, "WITHCODE")
+	}
+
+	r, err := redis.Values(conn.Do(command, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseFunctionLibraries(r)
+}
+
+func parseFunctionLibraries(r []interface{}) ([]FunctionLibrary, error) {
+	libraries := make([]FunctionLibrary, 0, len(r))
+	for _, libraryReply := range r {
+		fields, err := redis.Values(libraryReply, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		library := FunctionLibrary{Functions: []Function{}}
+		for i := 0; i+1 < len(fields); i += 2 {
+			name, _ := redis.String(fields[i], nil)
+			switch name {
+			case "library_name":
+				library.Name, _ = redis.String(fields[i+1], nil)
+			case "engine":
+				library.Engine, _ = redis.String(fields[i+1], nil)
+			case "library_code":
+				library.Code, _ = redis.String(fields[i+1], nil)
+			case "functions":
+				library.Functions, err = parseFunctions(fields[i+1])
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		libraries = append(libraries, library)
+	}
+
+	return libraries, nil
+}
+
+func parseFunctions(r interface{}) ([]Function, error) {
+	functionsReply, err := redis.Values(r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	functions := make([]Function, 0, len(functionsReply))
+	for _, functionReply := range functionsReply {
+		fields, err := redis.Values(functionReply, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		function := Function{Flags: []string{}}
+		for i := 0; i+1 < len(fields); i += 2 {
+			name, _ := redis.String(fields[i], nil)
+			switch name {
+			case "name":
+				function.Name, _ = redis.String(fields[i+1], nil)
+			case "description":
+				function.Description, _ = redis.String(fields[i+1], nil)
+			case "flags":
+				if flags, err := redis.Strings(fields[i+1], nil); err == nil {
+					function.Flags = flags
+				}
+			}
+		}
+		functions = append(functions, function)
+	}
+
+	return functions, nil
+}
+
+//LoadFunctionLibrary loads a library and returns its name, an existing library is replaced if replace is true
+func LoadFunctionLibrary(serverName, code string, replace bool) (string, error) {
+	command, err := functionChangeCommand(serverName)
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	args := []interface{}{"LOAD"}
+	if replace {
+		args = append(args, "REPLACE")
+	}
+	args = append(args, code)
+
+	return redis.String(conn.Do(command, args...))
+}
+
+//DeleteFunctionLibrary deletes a library with all its functions
+func DeleteFunctionLibrary(serverName, library string) error {
+	command, err := functionChangeCommand(serverName)
+	if err != nil {
+		return err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do(command, "DELETE", library)
+	return err
+}
+
+//CallFunction invokes a function with FCALL or with FCALL_RO if readOnly is true
+func CallFunction(serverName string, dbNum uint8, function string, keys, args []string, readOnly bool) (CommandReply, error) {
+	err := checkFunctionsSupported(serverName)
+	if err != nil {
+		return CommandReply{}, err
+	}
+
+	command := "FCALL"
+	if readOnly {
+		command = "FCALL_RO"
+	}
+
+	return runScriptCommand(serverName, dbNum, command, function, keys, args)
+}
+
+//CopyFunctions copies all libraries from one server to another with FUNCTION DUMP and FUNCTION RESTORE
+//policy is one of APPEND, REPLACE or FLUSH and defines how existing libraries of the target server are treated,
+//the target server commands policy is checked as libraries are changed there
+func CopyFunctions(fromServer, toServer, policy string) error {
+	policy = strings.ToUpper(policy)
+	if len(policy) == 0 {
+		policy = "APPEND"
+	}
+	if !functionsRestorePolicies[policy] {
+		return UnknownRestorePolicyError{Policy: policy}
+	}
+
+	fromCommand, err := functionCommand(fromServer)
+	if err != nil {
+		return err
+	}
+	toCommand, err := functionChangeCommand(toServer)
+	if err != nil {
+		return err
+	}
+
+	fromConn, err := connector.GetByName(fromServer, 0)
+	if err != nil {
+		return err
+	}
+	defer fromConn.Close()
+	payload, err := redis.Bytes(fromConn.Do(fromCommand, "DUMP"))
+	if err != nil {
+		return err
+	}
+
+	toConn, err := connector.GetByName(toServer, 0)
+	if err != nil {
+		return err
+	}
+	defer toConn.Close()
+	_, err = toConn.Do(toCommand, "RESTORE", payload, policy)
+	return err
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+)
+
+func TestGettingFunctions(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("INFO", "server").Expect([]byte("# Server\r\nredis_version:7.2.4\r\n"))
+	conn.Command("FUNCTION", "LIST", "LIBRARYNAME", "my*", "WITHCODE").Expect([]interface{}{
+		[]interface{}{
+			[]byte("library_name"), []byte("mylib"),
+			[]byte("engine"), []byte("LUA"),
+			[]byte("functions"), []interface{}{
+				[]interface{}{
+					[]byte("name"), []byte("myfunc"),
+					[]byte("description"), nil,
+					[]byte("flags"), []interface{}{[]byte("no-writes")},
+				},
+			},
+			[]byte("library_code"), []byte("#!lua name=mylib\nredis.register_function('myfunc', function() return 1 end)"),
+		},
+	})
+
+	libraries, err := GetFunctions("server1", "my*", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []FunctionLibrary{{
+		Name:      "mylib",
+		Engine:    "LUA",
+		Functions: []Function{{Name: "myfunc", Flags: []string{"no-writes"}}},
+		Code:      "#!lua name=mylib\nredis.register_function('myfunc', function() return 1 end)",
+	}}
+	if !reflect.DeepEqual(libraries, expected) {
+		t.Errorf("got libraries %+v, expected %+v", libraries, expected)
+	}
+}
+
+func TestFunctionsRequireRedis7(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("INFO", "server").Expect([]byte("# Server\r\nredis_version:6.2.14\r\n"))
+
+	_, err := GetFunctions("server1", "", false)
+	if _, ok := err.(FunctionsNotSupportedError); !ok {
+		t.Errorf("got error %v, expected FunctionsNotSupportedError", err)
+	}
+}
+
+func TestRefusingDeniedFunctionCommand(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	defer config.StubConfigLoader{}.Load()
+	servers := config.Get().Servers
+	srv := servers["server2"]
+	srv.DeniedCommands = []string{"function"}
+	servers["server2"] = srv
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("INFO", "server").Expect([]byte("# Server\r\nredis_version:7.2.4\r\n"))
+
+	_, err := LoadFunctionLibrary("server2", "#!lua name=mylib", false)
+	if _, ok := err.(CommandNotAllowedError); !ok {
+		t.Errorf("got error %v loading a library, expected CommandNotAllowedError", err)
+	}
+	err = DeleteFunctionLibrary("server2", "mylib")
+	if _, ok := err.(CommandNotAllowedError); !ok {
+		t.Errorf("got error %v deleting a library, expected CommandNotAllowedError", err)
+	}
+	err = CopyFunctions("server1", "server2", "APPEND")
+	if _, ok := err.(CommandNotAllowedError); !ok {
+		t.Errorf("got error %v copying libraries, expected CommandNotAllowedError", err)
+	}
+}
@@ -72,6 +72,7 @@ func (vInfo *HashValues) calculatePagesCount() error {
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
 
 	count := 0
 	if vInfo.query.Mask == "*" {
@@ -99,6 +100,7 @@ func (vInfo *HashValues) loadValues() error {
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
 
 	var (
 		values []string
@@ -146,6 +148,7 @@ func HashKeyExists(serverName string, dbNum uint8, key, hashKey string) (bool, e
 	if err != nil {
 		return false, err
 	}
+	defer conn.Close()
 
 	r, err := conn.Do("HEXISTS", key, hashKey)
 	exists, err := redis.Bool(r, err)
@@ -158,63 +161,39 @@ func HashKeyExists(serverName string, dbNum uint8, key, hashKey string) (bool, e
 }
 
 //SetHashKey sets a hash value
-func SetHashKey(serverName string, dbNum uint8, key, hashKey, hashValue string) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		return err
-	}
-
-	_, err = conn.Do("HSET", key, hashKey, hashValue)
-	if err != nil {
-		return err
-	}
-
-	return nil
+func SetHashKey(serverName string, dbNum uint8, key, hashKey, hashValue, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "set hash value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{newCommand("HSET", key, hashKey, hashValue)}, nil
+	})
 }
 
 //UpdateHashKey updates a value and hash key
-func UpdateHashKey(serverName string, dbNum uint8, key, hashKey, newHashKey, hashValue string) error {
-	if hashKey == newHashKey {
-		return SetHashKey(serverName, dbNum, key, hashKey, hashValue)
-	}
-
-	ex, err := HashKeyExists(serverName, dbNum, key, newHashKey)
-	if err != nil {
-		return err
-	}
-
-	if ex {
-		return fmt.Errorf("hash key %s already exists in hash %s", newHashKey, key)
-	}
-
-	conn, err := connector.GetByName(serverName, dbNum)
-	_, err = conn.Do("MULTI")
-	if err != nil {
-		return err
-	}
-
-	DeleteHashValue(serverName, dbNum, key, hashKey)
-	SetHashKey(serverName, dbNum, key, newHashKey, hashValue)
+//a renamed hash is watched, so the new hash key isn't overwritten if someone sets it after it's checked
+func UpdateHashKey(serverName string, dbNum uint8, key, hashKey, newHashKey, hashValue, ifMatch string) error {
+	renamed := hashKey != newHashKey
+	return updateWatchedKey(serverName, dbNum, key, "update hash value", ifMatch, renamed, func(conn redis.Conn) ([]command, error) {
+		if !renamed {
+			return []command{newCommand("HSET", key, hashKey, hashValue)}, nil
+		}
 
-	_, err = conn.Do("EXEC")
-	if err != nil {
-		return err
-	}
+		ex, err := redis.Bool(conn.Do("HEXISTS", key, newHashKey))
+		if err != nil {
+			return nil, err
+		}
+		if ex {
+			return nil, fmt.Errorf("hash key %s already exists in hash %s", newHashKey, key)
+		}
 
-	return nil
+		return []command{
+			newCommand("HDEL", key, hashKey),
+			newCommand("HSET", key, newHashKey, hashValue),
+		}, nil
+	})
 }
 
 //DeleteHashValue deletes a Hash value
-func DeleteHashValue(serverName string, dbNum uint8, key, hashKey string) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		return err
-	}
-
-	_, err = conn.Do("HDEL", key, hashKey)
-	if err != nil {
-		return err
-	}
-
-	return nil
+func DeleteHashValue(serverName string, dbNum uint8, key, hashKey, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "delete hash value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{newCommand("HDEL", key, hashKey)}, nil
+	})
 }
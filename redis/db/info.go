@@ -0,0 +1,163 @@
+package db
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+//GetServerInfo returns parsed INFO output for a given section
+//all sections are returned if section is empty
+func GetServerInfo(serverName, section string) (rd.ServerInfo, error) {
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return rd.ServerInfo{}, err
+	}
+	defer conn.Close()
+
+	if len(section) == 0 {
+		section = "all"
+	}
+
+	r, err := conn.Do("INFO", section)
+	info, err := redis.String(r, err)
+	if err != nil {
+		return rd.ServerInfo{}, err
+	}
+
+	return parseInfo(info), nil
+}
+
+func parseInfo(info string) rd.ServerInfo {
+	raw := parseRawInfo(info)
+
+	result := rd.ServerInfo{
+		CommandStats: make(map[string]rd.CommandStat),
+		ErrorStats:   make(map[string]int64),
+		Keyspace:     make(map[string]rd.ServerKeyspaceStat),
+		Raw:          raw,
+	}
+	fillInfoSection(raw["server"], &result.Server)
+	fillInfoSection(raw["clients"], &result.Clients)
+	fillInfoSection(raw["memory"], &result.Memory)
+	fillInfoSection(raw["persistence"], &result.Persistence)
+	fillInfoSection(raw["stats"], &result.Stats)
+	fillInfoSection(raw["replication"], &result.Replication)
+	fillInfoSection(raw["cpu"], &result.CPU)
+
+	replicas := make(map[int]string)
+	for name, value := range raw["replication"] {
+		//replicas are reported as slave0:ip=127.0.0.1,port=6380,state=online,offset=42,lag=0
+		if !strings.HasPrefix(name, "slave") || !strings.Contains(value, "=") {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(name, "slave")); err == nil {
+			replicas[n] = value
+		}
+	}
+	replicaNums := make([]int, 0, len(replicas))
+	for n := range replicas {
+		replicaNums = append(replicaNums, n)
+	}
+	sort.Ints(replicaNums)
+	for _, n := range replicaNums {
+		fields := parseInfoValueFields(replicas[n])
+		replica := rd.ReplicaInfo{IP: fields["ip"], State: fields["state"]}
+		replica.Port, _ = strconv.ParseInt(fields["port"], 10, 64)
+		replica.Offset, _ = strconv.ParseInt(fields["offset"], 10, 64)
+		replica.Lag, _ = strconv.ParseInt(fields["lag"], 10, 64)
+		result.Replication.Replicas = append(result.Replication.Replicas, replica)
+	}
+
+	for name, value := range raw["commandstats"] {
+		fields := parseInfoValueFields(value)
+		stat := rd.CommandStat{}
+		stat.Calls, _ = strconv.ParseInt(fields["calls"], 10, 64)
+		stat.Usec, _ = strconv.ParseInt(fields["usec"], 10, 64)
+		stat.UsecPerCall, _ = strconv.ParseFloat(fields["usec_per_call"], 64)
+		stat.RejectedCalls, _ = strconv.ParseInt(fields["rejected_calls"], 10, 64)
+		stat.FailedCalls, _ = strconv.ParseInt(fields["failed_calls"], 10, 64)
+		result.CommandStats[strings.TrimPrefix(name, "cmdstat_")] = stat
+	}
+
+	for name, value := range raw["errorstats"] {
+		count, _ := strconv.ParseInt(parseInfoValueFields(value)["count"], 10, 64)
+		result.ErrorStats[strings.TrimPrefix(name, "errorstat_")] = count
+	}
+
+	for name, value := range raw["keyspace"] {
+		result.Keyspace[name] = parseKeyspaceStatString(name + ":" + value)
+	}
+
+	return result
+}
+
+//parseRawInfo splits INFO output into sections, section names are lower-cased
+func parseRawInfo(info string) map[string]map[string]string {
+	sections := make(map[string]map[string]string)
+	section := ""
+	for _, row := range strings.Split(info, "\r\n") {
+		if len(row) == 0 {
+			continue
+		}
+		if strings.HasPrefix(row, "#") {
+			section = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(row, "#")))
+			continue
+		}
+
+		splittedRow := strings.SplitN(row, ":", 2)
+		if len(splittedRow) != 2 {
+			continue
+		}
+		if _, prs := sections[section]; !prs {
+			sections[section] = make(map[string]string)
+		}
+		sections[section][splittedRow[0]] = splittedRow[1]
+	}
+
+	return sections
+}
+
+//parseInfoValueFields parses values like calls=2,usec=15,usec_per_call=7.50
+func parseInfoValueFields(value string) map[string]string {
+	fields := make(map[string]string)
+	for _, field := range strings.Split(value, ",") {
+		splittedField := strings.SplitN(field, "=", 2)
+		if len(splittedField) == 2 {
+			fields[splittedField[0]] = splittedField[1]
+		}
+	}
+
+	return fields
+}
+
+//fillInfoSection sets section struct fields from INFO fields named in 'info' struct tags
+func fillInfoSection(fields map[string]string, section interface{}) {
+	v := reflect.ValueOf(section).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("info")
+		value, prs := fields[name]
+		if len(name) == 0 || !prs {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int64:
+			intValue, _ := strconv.ParseInt(value, 10, 64)
+			field.SetInt(intValue)
+		case reflect.Float64:
+			floatValue, _ := strconv.ParseFloat(value, 64)
+			field.SetFloat(floatValue)
+		case reflect.Bool:
+			field.SetBool(value == "1" || value == "yes")
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/redis"
+)
+
+var infoOutput = strings.Join([]string{
+	"# Server",
+	"redis_version:7.0.11",
+	"redis_mode:standalone",
+	"tcp_port:6379",
+	"uptime_in_seconds:3600",
+	"",
+	"# Clients",
+	"connected_clients:12",
+	"blocked_clients:1",
+	"",
+	"# Memory",
+	"used_memory:1048576",
+	"used_memory_human:1.00M",
+	"maxmemory_policy:allkeys-lru",
+	"mem_fragmentation_ratio:1.25",
+	"",
+	"# Persistence",
+	"rdb_bgsave_in_progress:0",
+	"rdb_last_bgsave_status:ok",
+	"aof_enabled:1",
+	"",
+	"# Stats",
+	"instantaneous_ops_per_sec:42",
+	"keyspace_hits:90",
+	"keyspace_misses:10",
+	"",
+	"# Replication",
+	"role:master",
+	"connected_slaves:4",
+	"slave10:ip=10.0.0.12,port=6380,state=online,offset=1200,lag=0",
+	"slave0:ip=10.0.0.2,port=6380,state=online,offset=1200,lag=1",
+	"slave2:ip=10.0.0.4,port=6380,state=wait_bgsave,offset=0,lag=0",
+	"slave1:ip=10.0.0.3,port=6380,state=online,offset=1100,lag=2",
+	"master_repl_offset:1234",
+	"",
+	"# CPU",
+	"used_cpu_sys:1.50",
+	"",
+	"# Commandstats",
+	"cmdstat_get:calls=2,usec=15,usec_per_call=7.50,rejected_calls=0,failed_calls=0",
+	"cmdstat_config|get:calls=1,usec=20,usec_per_call=20.00,rejected_calls=0,failed_calls=1",
+	"",
+	"# Errorstats",
+	"errorstat_ERR:count=3",
+	"",
+	"# Keyspace",
+	"db0:keys=5,expires=2,avg_ttl=100",
+	"db3:keys=1,expires=0,avg_ttl=0",
+	"",
+}, "\r\n")
+
+func TestGettingServerInfo(t *testing.T) {
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("INFO", "all").Expect(infoOutput)
+
+	info, err := GetServerInfo("server1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Server.RedisVersion != "7.0.11" || info.Server.TCPPort != 6379 || info.Server.UptimeInSeconds != 3600 {
+		t.Errorf("got invalid server section %+v", info.Server)
+	}
+	if info.Clients.ConnectedClients != 12 || info.Clients.BlockedClients != 1 {
+		t.Errorf("got invalid clients section %+v", info.Clients)
+	}
+	if info.Memory.UsedMemory != 1048576 || info.Memory.MaxMemoryPolicy != "allkeys-lru" || info.Memory.MemFragmentationRatio != 1.25 {
+		t.Errorf("got invalid memory section %+v", info.Memory)
+	}
+	if info.Persistence.RDBBgsaveInProgress || !info.Persistence.AOFEnabled || info.Persistence.RDBLastBgsaveStatus != "ok" {
+		t.Errorf("got invalid persistence section %+v", info.Persistence)
+	}
+	if info.CPU.UsedCPUSys != 1.5 {
+		t.Errorf("got invalid cpu section %+v", info.CPU)
+	}
+
+	expectedReplicas := []redis.ReplicaInfo{
+		redis.ReplicaInfo{IP: "10.0.0.2", Port: 6380, State: "online", Offset: 1200, Lag: 1},
+		redis.ReplicaInfo{IP: "10.0.0.3", Port: 6380, State: "online", Offset: 1100, Lag: 2},
+		redis.ReplicaInfo{IP: "10.0.0.4", Port: 6380, State: "wait_bgsave"},
+		redis.ReplicaInfo{IP: "10.0.0.12", Port: 6380, State: "online", Offset: 1200},
+	}
+	if info.Replication.Role != "master" || info.Replication.MasterReplOffset != 1234 || !reflect.DeepEqual(info.Replication.Replicas, expectedReplicas) {
+		t.Errorf("got invalid replication section %+v", info.Replication)
+	}
+
+	expectedCommandStats := map[string]redis.CommandStat{
+		"get":        redis.CommandStat{Calls: 2, Usec: 15, UsecPerCall: 7.5},
+		"config|get": redis.CommandStat{Calls: 1, Usec: 20, UsecPerCall: 20, FailedCalls: 1},
+	}
+	if !reflect.DeepEqual(info.CommandStats, expectedCommandStats) {
+		t.Errorf("got invalid commandstats %v, expected %v", info.CommandStats, expectedCommandStats)
+	}
+
+	if !reflect.DeepEqual(info.ErrorStats, map[string]int64{"ERR": 3}) {
+		t.Errorf("got invalid errorstats %v", info.ErrorStats)
+	}
+
+	expectedKeyspace := map[string]redis.ServerKeyspaceStat{
+		"db0": redis.ServerKeyspaceStat{KeysCount: 5, Expires: 2, AvgTTL: 100},
+		"db3": redis.ServerKeyspaceStat{KeysCount: 1},
+	}
+	if !reflect.DeepEqual(info.Keyspace, expectedKeyspace) {
+		t.Errorf("got invalid keyspace %v, expected %v", info.Keyspace, expectedKeyspace)
+	}
+
+	if info.Raw["stats"]["keyspace_hits"] != "90" {
+		t.Errorf("raw fields are missing: %v", info.Raw)
+	}
+}
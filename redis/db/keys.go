@@ -58,6 +58,7 @@ func FindKeysByMask(serverName string, dbNum uint8, mask string) ([]string, erro
 	if err != nil {
 		return nil, err
 	}
+	defer conn.Close()
 
 	result, err := conn.Do("KEYS", mask)
 	if err != nil {
@@ -69,7 +70,8 @@ func FindKeysByMask(serverName string, dbNum uint8, mask string) ([]string, erro
 }
 
 //FindKeysTreeNodeChildren returns the first generation of children for given keys tree node
-func FindKeysTreeNodeChildren(serverName string, dbNum uint8, delimiter string, pageSize int32, node KeyTreeNode) ([]KeyTreeNode, error) {
+//only keys passing the filter are included in the tree, all keys are included if the filter is nil
+func FindKeysTreeNodeChildren(serverName string, dbNum uint8, delimiter string, pageSize int32, node KeyTreeNode, filter func(key string) bool) ([]KeyTreeNode, error) {
 	var maskForSearch = node.Name
 	if maskForSearch != "*" {
 		maskForSearch = maskForSearch + delimiter + "*"
@@ -80,6 +82,7 @@ func FindKeysTreeNodeChildren(serverName string, dbNum uint8, delimiter string,
 	if err != nil {
 		return nil, err
 	}
+	defer conn.Close()
 
 	r, err := conn.Do("KEYS", maskForSearch)
 	if err != nil {
@@ -87,10 +90,24 @@ func FindKeysTreeNodeChildren(serverName string, dbNum uint8, delimiter string,
 	}
 
 	keys, _ := redis.Strings(r, nil)
+	if filter != nil {
+		keys = FilterKeys(keys, filter)
+	}
 
 	return getChildrenFromKeys(keys, maskForSearch, delimiter), nil
 }
 
+//FilterKeys returns keys passing the filter
+func FilterKeys(keys []string, filter func(key string) bool) []string {
+	result := []string{}
+	for _, key := range keys {
+		if filter(key) {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
 func getChildrenFromKeys(keys []string, maskForSearch, delimiter string) []KeyTreeNode {
 	var childKeysMap = make(map[string]KeyTreeNode)
 
@@ -140,6 +157,7 @@ func KeyExists(serverName string, dbNum uint8, key string) (bool, error) {
 		logger.Error(err)
 		return false, err
 	}
+	defer conn.Close()
 
 	r, err := conn.Do("EXISTS", key)
 	keyExists, err := redis.Bool(r, err)
@@ -161,6 +179,7 @@ func GetKeyInfo(serverName string, dbNum uint8, key string) (Key, error) {
 		logger.Critical(err)
 		return nil, errors.New("can't connect to server " + serverName)
 	}
+	defer conn.Close()
 
 	result, err := conn.Do("TYPE", key)
 	if err != nil {
@@ -185,21 +204,43 @@ func GetKeyInfo(serverName string, dbNum uint8, key string) (Key, error) {
 	return nil, errors.New("get unknown redis object type")
 }
 
-//DeleteKey deletes a given key
-func DeleteKey(serverName string, dbNum uint8, key string) error {
-	conn, err := connector.GetByName(serverName, dbNum)
+//DeleteKey deletes a given key, the key is moved to the trash if it's configured for the server
+//a trash on the server is written within the same transaction as the key is deleted in,
+//a local trash is written only after the key is deleted
+func DeleteKey(serverName string, dbNum uint8, key, ifMatch string) error {
+	t, err := serverTrash(serverName)
+	if _, ok := err.(TrashNotConfiguredError); ok {
+		return updateKey(serverName, dbNum, key, "delete key", ifMatch, func(conn redis.Conn) ([]command, error) {
+			return []command{newCommand("DEL", key)}, nil
+		})
+	}
 	if err != nil {
-		logger.Critical(err)
 		return err
 	}
 
-	_, err = conn.Do("DEL", key)
-	if err != nil {
-		logger.Error(err)
+	var trashed TrashedKey
+	var dump []byte
+	var exists bool
+	err = updateKey(serverName, dbNum, key, "delete key", ifMatch, func(conn redis.Conn) ([]command, error) {
+		var err error
+		trashed, dump, exists, err = trashedKey(conn, serverName, dbNum, key)
+		if err != nil {
+			return nil, err
+		}
+
+		commands := []command{newCommand("DEL", key)}
+		if rt, ok := t.(redisTrash); ok && exists {
+			commands = append(commands, newCommand("SELECT", rt.db))
+			commands = append(commands, rt.putCommands(trashed, dump)...)
+			commands = append(commands, newCommand("SELECT", dbNum))
+		}
+		return commands, nil
+	})
+	if _, ok := t.(localTrash); !ok || err != nil || !exists {
 		return err
 	}
 
-	return nil
+	return t.put(trashed, dump)
 }
 
 func getValuesPagesCount(valuesCout int, pageSize int) int {
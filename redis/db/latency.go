@@ -0,0 +1,199 @@
+package db
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+//LatencyEvent is a latest latency spike of an event reported by LATENCY LATEST
+type LatencyEvent struct {
+	Name      string
+	Timestamp int64
+	LatestMs  int64
+	MaxMs     int64
+}
+
+//LatencySample is a latency spike of an event reported by LATENCY HISTORY
+type LatencySample struct {
+	Timestamp int64
+	LatencyMs int64
+}
+
+func latencyConn(serverName, command string) (redis.Conn, string, error) {
+	command, err := serverCommand(serverName, command)
+	if err != nil {
+		return nil, "", err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	return conn, command, err
+}
+
+//GetLatencyLatest returns the latest latency spikes of all events
+func GetLatencyLatest(serverName string) ([]LatencyEvent, error) {
+	conn, command, err := latencyConn(serverName, "LATENCY")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	r, err := redis.Values(conn.Do(command, "LATEST"))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]LatencyEvent, 0, len(r))
+	for _, eventReply := range r {
+		fields, err := redis.Values(eventReply, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) < 4 {
+			continue
+		}
+
+		event := LatencyEvent{}
+		event.Name, _ = redis.String(fields[0], nil)
+		event.Timestamp, _ = redis.Int64(fields[1], nil)
+		event.LatestMs, _ = redis.Int64(fields[2], nil)
+		event.MaxMs, _ = redis.Int64(fields[3], nil)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+//GetLatencyHistory returns latency spikes of an event
+func GetLatencyHistory(serverName, event string) ([]LatencySample, error) {
+	conn, command, err := latencyConn(serverName, "LATENCY")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	r, err := redis.Values(conn.Do(command, "HISTORY", event))
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]LatencySample, 0, len(r))
+	for _, sampleReply := range r {
+		fields, err := redis.Int64s(sampleReply, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		samples = append(samples, LatencySample{Timestamp: fields[0], LatencyMs: fields[1]})
+	}
+	return samples, nil
+}
+
+//GetLatencyDoctor returns a human readable latency analysis report
+func GetLatencyDoctor(serverName string) (string, error) {
+	conn, command, err := latencyConn(serverName, "LATENCY")
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	return redis.String(conn.Do(command, "DOCTOR"))
+}
+
+//GetMemoryDoctor returns a human readable memory problems report
+func GetMemoryDoctor(serverName string) (string, error) {
+	conn, command, err := latencyConn(serverName, "MEMORY")
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	return redis.String(conn.Do(command, "DOCTOR"))
+}
+
+//GetMemoryStats returns MEMORY STATS output as a map, nested stats like per-database overhead are maps too
+func GetMemoryStats(serverName string) (map[string]interface{}, error) {
+	conn, command, err := latencyConn(serverName, "MEMORY")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	r, err := redis.Values(conn.Do(command, "STATS"))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMemoryStats(r), nil
+}
+
+func parseMemoryStats(fields []interface{}) map[string]interface{} {
+	stats := make(map[string]interface{})
+	for i := 0; i+1 < len(fields); i += 2 {
+		name, err := redis.String(fields[i], nil)
+		if err != nil {
+			continue
+		}
+
+		switch v := fields[i+1].(type) {
+		case []interface{}:
+			stats[name] = parseMemoryStats(v)
+		case []byte:
+			//ratios and percentages are returned as bulk strings
+			if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+				stats[name] = f
+			} else {
+				stats[name] = string(v)
+			}
+		default:
+			stats[name] = v
+		}
+	}
+	return stats
+}
+
+//LatencyProbe measures PING round-trip time on a dedicated connection
+type LatencyProbe struct {
+	serverName string
+	conn       redis.Conn
+}
+
+//NewLatencyProbe returns a probe for a server, the connection is established with the first ping
+func NewLatencyProbe(serverName string) *LatencyProbe {
+	return &LatencyProbe{serverName: serverName}
+}
+
+//Ping sends PING to the server and returns round-trip time, the connection is reestablished after errors
+func (p *LatencyProbe) Ping() (time.Duration, error) {
+	command, err := serverCommand(p.serverName, "PING")
+	if err != nil {
+		return 0, err
+	}
+
+	if p.conn == nil {
+		p.conn, err = connector.Dial(p.serverName)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	started := time.Now()
+	_, err = p.conn.Do(command)
+	rtt := time.Since(started)
+	if err != nil {
+		p.Close()
+		return 0, err
+	}
+
+	return rtt, nil
+}
+
+//Close closes the probe connection
+func (p *LatencyProbe) Close() {
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+)
+
+func TestGettingLatencyLatest(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("LATENCY", "LATEST").Expect([]interface{}{
+		[]interface{}{[]byte("command"), int64(1405067976), int64(251), int64(1001)},
+		[]interface{}{[]byte("fast-command"), int64(1405067822), int64(25), int64(30)},
+	})
+
+	events, err := GetLatencyLatest("server1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []LatencyEvent{
+		{Name: "command", Timestamp: 1405067976, LatestMs: 251, MaxMs: 1001},
+		{Name: "fast-command", Timestamp: 1405067822, LatestMs: 25, MaxMs: 30},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("got events %+v, expected %+v", events, expected)
+	}
+}
+
+func TestParsingMemoryStats(t *testing.T) {
+	stats := parseMemoryStats([]interface{}{
+		[]byte("peak.allocated"), int64(1048576),
+		[]byte("db.0"), []interface{}{[]byte("overhead.hashtable.main"), int64(72)},
+		[]byte("dataset.percentage"), []byte("34.5"),
+	})
+
+	expected := map[string]interface{}{
+		"peak.allocated":     int64(1048576),
+		"db.0":               map[string]interface{}{"overhead.hashtable.main": int64(72)},
+		"dataset.percentage": 34.5,
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("got stats %+v, expected %+v", stats, expected)
+	}
+}
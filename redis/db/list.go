@@ -1,6 +1,9 @@
 package db
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+
 	"github.com/garyburd/redigo/redis"
 	"github.com/sad0vnikov/radish/logger"
 )
@@ -115,6 +118,7 @@ func (vInfo *ListValues) loadUnmaskedValues() error {
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
 
 	pageStart := (vInfo.query.PageNum - 1) * vInfo.query.PageSize
 	pageEnd := vInfo.query.PageNum*vInfo.query.PageSize - 1
@@ -144,6 +148,7 @@ func (vInfo *ListValues) loadMaskedValues() error {
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
 
 	pageStart := (vInfo.query.PageNum - 1) * vInfo.query.PageSize
 	pageEnd := vInfo.query.PageNum*vInfo.query.PageSize - 1
@@ -181,6 +186,7 @@ func (vInfo *ListValues) loadUnmaskedValuesCount() error {
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
 	r, err := conn.Do("LLEN", vInfo.key.key)
 	count, err := redis.Int(r, err)
 	vInfo.totalValuesCount = count
@@ -190,84 +196,56 @@ func (vInfo *ListValues) loadUnmaskedValuesCount() error {
 //InsertToListWithPos inserts a value at the given position
 //If there are vInfo after the given index, they are moved to the right
 //If position greater then the last list index, the value will be added to the and of the list
-func InsertToListWithPos(serverName string, dbNum uint8, key, listValue string, position int) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		return err
-	}
-
-	r, err := conn.Do("LINDEX", key, position)
-	valueAfter, err := redis.String(r, err)
-	if err != nil && err != redis.ErrNil {
-		logger.Error(err)
-		return err
-	}
-
-	if len(valueAfter) != 0 {
-		_, err = conn.Do("LINSERT", key, "BEFORE", valueAfter, listValue)
-	} else {
-		_, err = conn.Do("RPUSH", key, listValue)
-	}
-
-	if err != nil {
-		logger.Error(err)
-		return err
-	}
+//the list is watched, so the value isn't inserted at another position if someone changes the list after it's read
+func InsertToListWithPos(serverName string, dbNum uint8, key, listValue string, position int, ifMatch string) error {
+	return updateWatchedKey(serverName, dbNum, key, "insert list value", ifMatch, true, func(conn redis.Conn) ([]command, error) {
+		valueAfter, err := redis.String(conn.Do("LINDEX", key, position))
+		if err != nil && err != redis.ErrNil {
+			return nil, err
+		}
 
-	return nil
+		if len(valueAfter) != 0 {
+			return []command{newCommand("LINSERT", key, "BEFORE", valueAfter, listValue)}, nil
+		}
+		return []command{newCommand("RPUSH", key, listValue)}, nil
+	})
 }
 
 //AppendToList appends a value to the end of list
-func AppendToList(serverName string, dbNum uint8, key, listValue string) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		return err
-	}
-
-	_, err = conn.Do("RPUSH", key, listValue)
-	if err != nil {
-		logger.Error(err)
-		return err
-	}
-
-	return nil
+func AppendToList(serverName string, dbNum uint8, key, listValue, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "append list value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{newCommand("RPUSH", key, listValue)}, nil
+	})
 }
 
 //UpdateListValue updates a list Value by index
-func UpdateListValue(serverName string, dbNum uint8, key string, index int, newValue string) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		return err
-	}
-
-	_, err = conn.Do("LSET", key, index, newValue)
-	if err != nil {
-		logger.Error(err)
-		return err
-	}
-
-	return nil
+func UpdateListValue(serverName string, dbNum uint8, key string, index int, newValue, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "update list value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{newCommand("LSET", key, index, newValue)}, nil
+	})
 }
 
-//DeleteListValue removes List member
-func DeleteListValue(serverName string, dbNum uint8, key string, index int) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		return err
-	}
+//deleteListValueScript replaces a list member with a placeholder and removes the placeholder,
+//the script fails at LSET if the index is out of range, so nothing is removed then
+const deleteListValueScript = `redis.call('LSET', KEYS[1], ARGV[1], ARGV[2])
+return redis.call('LREM', KEYS[1], 1, ARGV[2])`
 
-	const deletedValue = "RADISH_DELETED"
-	_, err = conn.Do("LSET", key, index, deletedValue)
+//DeleteListValue removes List member
+//the member is removed with a Lua script using a random placeholder, so other members are never removed instead of it
+func DeleteListValue(serverName string, dbNum uint8, key string, index int, ifMatch string) error {
+	evalCommand, err := serverCommand(serverName, "EVAL")
 	if err != nil {
-		logger.Error(err)
 		return err
 	}
 
-	_, err = conn.Do("LREM", key, 1, deletedValue)
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
 	if err != nil {
-		logger.Error(err)
 		return err
 	}
+	placeholder := "RADISH_DELETED_" + hex.EncodeToString(b)
 
-	return nil
+	return updateKey(serverName, dbNum, key, "delete list value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{newCommand(evalCommand, deleteListValueScript, 1, key, index, placeholder)}, nil
+	})
 }
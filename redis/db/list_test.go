@@ -0,0 +1,30 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+)
+
+func TestDeletingListValueWithScript(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("DUMP", "queue").Expect([]byte("dumped"))
+	conn.Command("TYPE", "queue").Expect("list")
+	conn.Command("PTTL", "queue").Expect(int64(-1))
+	eval := conn.GenericCommand("EVAL").Expect(int64(1))
+	lset := conn.GenericCommand("LSET").Expect("OK")
+	lrem := conn.GenericCommand("LREM").Expect(int64(1))
+
+	err := DeleteListValue("server1", 0, "queue", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Stats(eval) != 1 || conn.Stats(lset) != 0 || conn.Stats(lrem) != 0 {
+		t.Errorf("expected the value to be deleted with a single script call")
+	}
+}
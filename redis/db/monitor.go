@@ -0,0 +1,303 @@
+package db
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+//MonitoredCommand is a command captured with MONITOR
+type MonitoredCommand struct {
+	Time       float64
+	DB         int64
+	ClientAddr string
+	Args       []string
+}
+
+//Command returns an upper-cased name of monitored command
+func (c MonitoredCommand) Command() string {
+	if len(c.Args) == 0 {
+		return ""
+	}
+	return strings.ToUpper(c.Args[0])
+}
+
+//Key returns the first key of monitored command or an empty string for commands without keys
+func (c MonitoredCommand) Key() string {
+	if len(c.Args) < 2 || keylessCommands[c.Command()] {
+		return ""
+	}
+	return c.Args[1]
+}
+
+var keylessCommands = map[string]bool{
+	"AUTH": true, "CLIENT": true, "CLUSTER": true, "COMMAND": true, "CONFIG": true, "DBSIZE": true,
+	"DEBUG": true, "ECHO": true, "EVAL": true, "EVALSHA": true, "EXEC": true, "FCALL": true,
+	"FCALL_RO": true, "FLUSHALL": true, "FLUSHDB": true, "FUNCTION": true, "HELLO": true, "INFO": true,
+	"KEYS": true, "LATENCY": true, "MEMORY": true, "MULTI": true, "PING": true, "PUBLISH": true,
+	"PSUBSCRIBE": true, "SCAN": true, "SCRIPT": true, "SELECT": true, "SLOWLOG": true, "SUBSCRIBE": true,
+	"TIME": true, "UNWATCH": true, "ACL": true,
+}
+
+//MonitorFilter selects monitored commands, empty fields match any command
+//KeyPattern and ClientAddr are masks
+type MonitorFilter struct {
+	Command    string
+	KeyPattern string
+	ClientAddr string
+}
+
+//Matches returns true if a command satisfies the filter
+func (f MonitorFilter) Matches(c MonitoredCommand) bool {
+	if len(f.Command) > 0 && !strings.EqualFold(f.Command, c.Command()) {
+		return false
+	}
+	if len(f.KeyPattern) > 0 && !matchStringValueWithMask(c.Key(), f.KeyPattern) {
+		return false
+	}
+	if len(f.ClientAddr) > 0 && !matchStringValueWithMask(c.ClientAddr, f.ClientAddr) {
+		return false
+	}
+	return true
+}
+
+//Frequency is a count of monitored commands having the same command name, key or key prefix
+type Frequency struct {
+	Name  string
+	Count int64
+}
+
+//MonitorSummary is a statistics of commands captured during MONITOR session
+type MonitorSummary struct {
+	Duration      float64
+	CommandsCount int64
+	Commands      []Frequency
+	HotKeys       []Frequency
+	HotPrefixes   []Frequency
+}
+
+//MonitorStats accumulates monitored commands statistics
+type MonitorStats struct {
+	delimiter string
+	count     int64
+	commands  map[string]int64
+	keys      map[string]int64
+	prefixes  map[string]int64
+}
+
+//NewMonitorStats returns empty MonitorStats, key prefixes are split by delimiter
+func NewMonitorStats(delimiter string) *MonitorStats {
+	return &MonitorStats{
+		delimiter: delimiter,
+		commands:  make(map[string]int64),
+		keys:      make(map[string]int64),
+		prefixes:  make(map[string]int64),
+	}
+}
+
+//Add counts a monitored command
+func (stats *MonitorStats) Add(c MonitoredCommand) {
+	stats.count++
+	stats.commands[c.Command()]++
+	if key := c.Key(); len(key) > 0 {
+		stats.keys[key]++
+		stats.prefixes[strings.Split(key, stats.delimiter)[0]]++
+	}
+}
+
+//Summary returns the commands frequencies and top hottest keys and prefixes
+func (stats *MonitorStats) Summary(top int) MonitorSummary {
+	return MonitorSummary{
+		CommandsCount: stats.count,
+		Commands:      topFrequencies(stats.commands, 0),
+		HotKeys:       topFrequencies(stats.keys, top),
+		HotPrefixes:   topFrequencies(stats.prefixes, top),
+	}
+}
+
+const monitorTopSize = 20
+
+//Monitor captures commands processed by a server during given duration on a dedicated connection
+//every command matching the filter is passed to onCommand
+//capturing stops early if onCommand returns an error or done channel is closed
+func Monitor(serverName string, duration time.Duration, filter MonitorFilter, delimiter string, done <-chan struct{}, onCommand func(MonitoredCommand) error) (MonitorSummary, error) {
+	conn, err := connector.Dial(serverName)
+	if err != nil {
+		return MonitorSummary{}, err
+	}
+	defer conn.Close()
+
+	monitorCommand, err := serverCommand(serverName, "MONITOR")
+	if err != nil {
+		return MonitorSummary{}, err
+	}
+
+	_, err = conn.Do(monitorCommand)
+	if err != nil {
+		return MonitorSummary{}, err
+	}
+
+	//the connection is closed when the time is over to interrupt waiting for the next command
+	var timeIsOver int32
+	started := time.Now()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-done:
+		case <-stop:
+			return
+		}
+		atomic.StoreInt32(&timeIsOver, 1)
+		conn.Close()
+	}()
+
+	stats := NewMonitorStats(delimiter)
+	for {
+		r, err := receiveWithoutTimeout(conn)
+		if atomic.LoadInt32(&timeIsOver) == 1 {
+			break
+		}
+		if err != nil {
+			return MonitorSummary{}, err
+		}
+
+		line, err := redis.String(r, err)
+		if err != nil {
+			continue
+		}
+		c, err := parseMonitorLine(line)
+		if err != nil || !filter.Matches(c) {
+			continue
+		}
+
+		stats.Add(c)
+		if err := onCommand(c); err != nil {
+			break
+		}
+	}
+
+	summary := stats.Summary(monitorTopSize)
+	summary.Duration = time.Since(started).Seconds()
+	return summary, nil
+}
+
+//receiveWithoutTimeout waits for a reply ignoring the connection read timeout, a monitored server may process no commands for a long time
+func receiveWithoutTimeout(conn redis.Conn) (interface{}, error) {
+	if cwt, ok := conn.(redis.ConnWithTimeout); ok {
+		return cwt.ReceiveWithTimeout(0)
+	}
+	return conn.Receive()
+}
+
+//parseMonitorLine parses lines like 1339518083.107412 [0 127.0.0.1:60866] "keys" "*"
+func parseMonitorLine(line string) (MonitoredCommand, error) {
+	c := MonitoredCommand{}
+
+	clientStart := strings.Index(line, " [")
+	clientEnd := strings.Index(line, "] ")
+	if clientStart == -1 || clientEnd < clientStart {
+		return c, errors.New("got malformed MONITOR line: " + line)
+	}
+
+	var err error
+	c.Time, err = strconv.ParseFloat(line[:clientStart], 64)
+	if err != nil {
+		return c, err
+	}
+
+	client := strings.SplitN(line[clientStart+2:clientEnd], " ", 2)
+	c.DB, err = strconv.ParseInt(client[0], 10, 64)
+	if err != nil {
+		return c, err
+	}
+	if len(client) == 2 {
+		c.ClientAddr = client[1]
+	}
+
+	c.Args, err = parseQuotedArgs(line[clientEnd+2:])
+	return c, err
+}
+
+//parseQuotedArgs parses space-separated double-quoted strings escaped the way Redis does
+func parseQuotedArgs(s string) ([]string, error) {
+	args := []string{}
+	for i := 0; i < len(s); i++ {
+		if s[i] == ' ' {
+			continue
+		}
+		if s[i] != '"' {
+			return nil, errors.New("got unquoted argument: " + s[i:])
+		}
+
+		arg := []byte{}
+		closed := false
+		for i++; i < len(s); i++ {
+			if s[i] == '"' {
+				closed = true
+				break
+			}
+			if s[i] != '\\' || i+1 == len(s) {
+				arg = append(arg, s[i])
+				continue
+			}
+
+			i++
+			switch s[i] {
+			case 'n':
+				arg = append(arg, '\n')
+			case 'r':
+				arg = append(arg, '\r')
+			case 't':
+				arg = append(arg, '\t')
+			case 'a':
+				arg = append(arg, '\a')
+			case 'b':
+				arg = append(arg, '\b')
+			case 'x':
+				if i+2 < len(s) {
+					if b, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+						arg = append(arg, byte(b))
+						i += 2
+						continue
+					}
+				}
+				arg = append(arg, 'x')
+			default:
+				arg = append(arg, s[i])
+			}
+		}
+		if !closed {
+			return nil, errors.New("got unterminated quoted argument")
+		}
+		args = append(args, string(arg))
+	}
+
+	return args, nil
+}
+
+func topFrequencies(counts map[string]int64, top int) []Frequency {
+	result := make([]Frequency, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, Frequency{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count == result[j].Count {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Count > result[j].Count
+	})
+
+	if top > 0 && len(result) > top {
+		result = result[:top]
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsingMonitorLine(t *testing.T) {
+	c, err := parseMonitorLine(`1339518083.107412 [2 127.0.0.1:60866] "set" "user:1" "say \"hi\"\n\xff"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := MonitoredCommand{Time: 1339518083.107412, DB: 2, ClientAddr: "127.0.0.1:60866", Args: []string{"set", "user:1", "say \"hi\"\n\xff"}}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("got %#v, expected %#v", c, expected)
+	}
+
+	c, err = parseMonitorLine(`1339518083.107412 [0 lua] "get" "counter"`)
+	if err != nil || c.ClientAddr != "lua" || c.Key() != "counter" {
+		t.Errorf("got %#v, %v", c, err)
+	}
+
+	if _, err := parseMonitorLine("OK"); err == nil {
+		t.Error("expected an error for malformed line")
+	}
+}
+
+func TestSummarizingMonitoredCommands(t *testing.T) {
+	lines := []string{
+		`1.1 [0 127.0.0.1:1] "get" "user:1"`,
+		`1.2 [0 127.0.0.1:1] "ping"`,
+		`1.3 [0 127.0.0.1:2] "GET" "user:1"`,
+		`1.4 [0 127.0.0.1:2] "hget" "session:7" "ttl"`,
+		`1.5 [0 127.0.0.1:2] "get" "user:2"`,
+	}
+
+	filter := MonitorFilter{KeyPattern: "*:*", ClientAddr: "127.0.0.1:2"}
+	stats := NewMonitorStats(":")
+	for _, l := range lines {
+		c, err := parseMonitorLine(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filter.Matches(c) {
+			stats.Add(c)
+		}
+	}
+
+	summary := stats.Summary(1)
+	if summary.CommandsCount != 3 {
+		t.Errorf("got %v commands, expected 3", summary.CommandsCount)
+	}
+	expectedCommands := []Frequency{Frequency{Name: "GET", Count: 2}, Frequency{Name: "HGET", Count: 1}}
+	if !reflect.DeepEqual(summary.Commands, expectedCommands) {
+		t.Errorf("got commands %v, expected %v", summary.Commands, expectedCommands)
+	}
+	if !reflect.DeepEqual(summary.HotKeys, []Frequency{Frequency{Name: "session:7", Count: 1}}) {
+		t.Errorf("got hot keys %v", summary.HotKeys)
+	}
+	if !reflect.DeepEqual(summary.HotPrefixes, []Frequency{Frequency{Name: "user", Count: 2}}) {
+		t.Errorf("got hot prefixes %v", summary.HotPrefixes)
+	}
+}
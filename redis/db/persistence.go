@@ -0,0 +1,147 @@
+package db
+
+import (
+	"github.com/garyburd/redigo/redis"
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+//BackgroundOperation is a started BGSAVE or BGREWRITEAOF
+//LastSave is LASTSAVE value before the operation started, it's used to detect when the save finishes
+type BackgroundOperation struct {
+	Message  string
+	LastSave int64 `json:",omitempty"`
+}
+
+//BackgroundOperationProgress is a state of BGSAVE or BGREWRITEAOF
+//Status is the last operation status reported by INFO persistence, e.g. 'ok' or 'err'
+type BackgroundOperationProgress struct {
+	InProgress     bool
+	Finished       bool
+	Status         string
+	ElapsedSeconds int64
+	LastSave       int64 `json:",omitempty"`
+}
+
+//GetPersistenceStatus returns RDB and AOF status
+func GetPersistenceStatus(serverName string) (rd.InfoPersistence, error) {
+	info, err := GetServerInfo(serverName, "persistence")
+	if err != nil {
+		return rd.InfoPersistence{}, err
+	}
+
+	return info.Persistence, nil
+}
+
+//GetLastSave returns the time of the last successful RDB save
+func GetLastSave(serverName string) (int64, error) {
+	command, err := serverCommand(serverName, "LASTSAVE")
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	return redis.Int64(conn.Do(command))
+}
+
+//StartBackgroundSave runs BGSAVE, the save is scheduled after running AOF rewrite if schedule is true
+func StartBackgroundSave(serverName string, schedule bool) (BackgroundOperation, error) {
+	lastSave, err := GetLastSave(serverName)
+	if err != nil {
+		return BackgroundOperation{}, err
+	}
+
+	command, err := serverCommand(serverName, "BGSAVE")
+	if err != nil {
+		return BackgroundOperation{}, err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return BackgroundOperation{}, err
+	}
+	defer conn.Close()
+
+	args := []interface{}{}
+	if schedule {
+		args = append(args, "SCHEDULE")
+	}
+
+	message, err := redis.String(conn.Do(command, args...))
+	if err != nil {
+		return BackgroundOperation{}, err
+	}
+
+	return BackgroundOperation{Message: message, LastSave: lastSave}, nil
+}
+
+//GetBackgroundSaveProgress returns BGSAVE progress, the save is finished when LASTSAVE changes from lastSave value
+//a save scheduled with BGSAVE SCHEDULE is reported as in progress while it waits for AOF rewrite
+func GetBackgroundSaveProgress(serverName string, lastSave int64) (BackgroundOperationProgress, error) {
+	status, err := GetPersistenceStatus(serverName)
+	if err != nil {
+		return BackgroundOperationProgress{}, err
+	}
+
+	saved := status.RDBLastSaveTime > lastSave
+	//BGSAVE is refused while AOF is rewritten unless it's scheduled, so a started save which hasn't run yet is scheduled then
+	scheduled := !status.RDBBgsaveInProgress && !saved && (status.AOFRewriteInProgress || status.AOFRewriteScheduled)
+
+	progress := BackgroundOperationProgress{
+		InProgress: status.RDBBgsaveInProgress || scheduled,
+		Status:     status.RDBLastBgsaveStatus,
+		LastSave:   status.RDBLastSaveTime,
+	}
+	if status.RDBBgsaveInProgress {
+		progress.ElapsedSeconds = status.RDBCurrentBgsaveTimeSec
+	}
+	//a failed save doesn't change LASTSAVE, so the save is also finished if it has run and failed,
+	//the status of a previous save is kept until a scheduled save runs
+	progress.Finished = !progress.InProgress && (saved || status.RDBLastBgsaveStatus == "err")
+
+	return progress, nil
+}
+
+//StartAOFRewrite runs BGREWRITEAOF
+func StartAOFRewrite(serverName string) (BackgroundOperation, error) {
+	command, err := serverCommand(serverName, "BGREWRITEAOF")
+	if err != nil {
+		return BackgroundOperation{}, err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return BackgroundOperation{}, err
+	}
+	defer conn.Close()
+
+	message, err := redis.String(conn.Do(command))
+	if err != nil {
+		return BackgroundOperation{}, err
+	}
+
+	return BackgroundOperation{Message: message}, nil
+}
+
+//GetAOFRewriteProgress returns BGREWRITEAOF progress, a scheduled rewrite is reported as in progress
+func GetAOFRewriteProgress(serverName string) (BackgroundOperationProgress, error) {
+	status, err := GetPersistenceStatus(serverName)
+	if err != nil {
+		return BackgroundOperationProgress{}, err
+	}
+
+	progress := BackgroundOperationProgress{
+		InProgress: status.AOFRewriteInProgress || status.AOFRewriteScheduled,
+		Status:     status.AOFLastBgrewriteStatus,
+	}
+	if status.AOFRewriteInProgress {
+		progress.ElapsedSeconds = status.AOFCurrentRewriteTimeSec
+	}
+	progress.Finished = !progress.InProgress
+
+	return progress, nil
+}
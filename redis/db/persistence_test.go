@@ -0,0 +1,53 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+)
+
+func TestGettingBackgroundSaveProgress(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("INFO", "persistence").Expect([]byte("# Persistence\r\nloading:0\r\nrdb_bgsave_in_progress:1\r\nrdb_last_save_time:1700000000\r\n" +
+		"rdb_last_bgsave_status:ok\r\nrdb_current_bgsave_time_sec:3\r\n"))
+
+	progress, err := GetBackgroundSaveProgress("server1", 1700000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := BackgroundOperationProgress{InProgress: true, Status: "ok", ElapsedSeconds: 3, LastSave: 1700000000}
+	if !reflect.DeepEqual(progress, expected) {
+		t.Errorf("got progress %+v, expected %+v", progress, expected)
+	}
+
+	conn.Clear()
+	conn.Command("INFO", "persistence").Expect([]byte("# Persistence\r\nloading:0\r\nrdb_bgsave_in_progress:0\r\nrdb_last_save_time:1700000005\r\n" +
+		"rdb_last_bgsave_status:ok\r\nrdb_current_bgsave_time_sec:-1\r\n"))
+
+	progress, err = GetBackgroundSaveProgress("server1", 1700000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = BackgroundOperationProgress{Finished: true, Status: "ok", LastSave: 1700000005}
+	if !reflect.DeepEqual(progress, expected) {
+		t.Errorf("got progress %+v, expected %+v", progress, expected)
+	}
+
+	conn.Clear()
+	conn.Command("INFO", "persistence").Expect([]byte("# Persistence\r\nloading:0\r\nrdb_bgsave_in_progress:0\r\nrdb_last_save_time:1700000000\r\n" +
+		"rdb_last_bgsave_status:err\r\nrdb_current_bgsave_time_sec:-1\r\naof_rewrite_in_progress:1\r\n"))
+
+	progress, err = GetBackgroundSaveProgress("server1", 1700000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !progress.InProgress || progress.Finished {
+		t.Errorf("got progress %+v, expected a save scheduled after AOF rewrite not to be finished by a previous failed save", progress)
+	}
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+//EvalScript runs a Lua script with EVAL
+func EvalScript(serverName string, dbNum uint8, script string, keys, args []string) (CommandReply, error) {
+	return runScriptCommand(serverName, dbNum, "EVAL", script, keys, args)
+}
+
+//EvalScriptSHA runs a Lua script loaded to the server script cache with EVALSHA
+func EvalScriptSHA(serverName string, dbNum uint8, sha string, keys, args []string) (CommandReply, error) {
+	return runScriptCommand(serverName, dbNum, "EVALSHA", sha, keys, args)
+}
+
+//RunScript runs a Lua script with EVALSHA, the script is sent with EVAL if it isn't in the server script cache yet
+func RunScript(serverName string, dbNum uint8, script string, keys, args []string) (CommandReply, error) {
+	reply, err := EvalScriptSHA(serverName, dbNum, ScriptSHA(script), keys, args)
+	if err != nil {
+		return reply, err
+	}
+	if reply.Type == ReplyError {
+		if msg, ok := reply.Value.(string); ok && strings.HasPrefix(msg, "NOSCRIPT") {
+			return EvalScript(serverName, dbNum, script, keys, args)
+		}
+	}
+
+	return reply, nil
+}
+
+//ScriptSHA returns a SHA1 digest Redis uses to identify a script
+func ScriptSHA(script string) string {
+	sum := sha1.Sum([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+func runScriptCommand(serverName string, dbNum uint8, command, script string, keys, args []string) (CommandReply, error) {
+	err := CheckCommandAllowed(serverName, command)
+	if err != nil {
+		return CommandReply{}, err
+	}
+
+	command, err = serverCommand(serverName, command)
+	if err != nil {
+		return CommandReply{}, err
+	}
+
+	conn, err := connector.GetByName(serverName, dbNum)
+	if err != nil {
+		return CommandReply{}, err
+	}
+	defer conn.Close()
+
+	commandArgs := make([]interface{}, 0, len(keys)+len(args)+2)
+	commandArgs = append(commandArgs, script, len(keys))
+	for _, key := range keys {
+		commandArgs = append(commandArgs, key)
+	}
+	for _, arg := range args {
+		commandArgs = append(commandArgs, arg)
+	}
+
+	return NewCommandReply(conn.Do(command, commandArgs...))
+}
+
+//LoadScript loads a Lua script to the server script cache and returns its SHA1 digest
+func LoadScript(serverName string, script string) (string, error) {
+	err := CheckCommandAllowed(serverName, "SCRIPT")
+	if err != nil {
+		return "", err
+	}
+
+	command, err := serverCommand(serverName, "SCRIPT")
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	return redis.String(conn.Do(command, "LOAD", script))
+}
+
+//ScriptsExist checks if scripts with given SHA1 digests are in the server script cache
+func ScriptsExist(serverName string, shas []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if len(shas) == 0 {
+		return result, nil
+	}
+
+	err := CheckCommandAllowed(serverName, "SCRIPT")
+	if err != nil {
+		return nil, err
+	}
+
+	command, err := serverCommand(serverName, "SCRIPT")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	commandArgs := []interface{}{"EXISTS"}
+	for _, sha := range shas {
+		commandArgs = append(commandArgs, sha)
+	}
+
+	exist, err := redis.Ints(conn.Do(command, commandArgs...))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, sha := range shas {
+		result[sha] = i < len(exist) && exist[i] == 1
+	}
+	return result, nil
+}
+
+//FlushScripts removes all scripts from the server script cache
+func FlushScripts(serverName string, async bool) error {
+	err := CheckCommandAllowed(serverName, "SCRIPT")
+	if err != nil {
+		return err
+	}
+
+	command, err := serverCommand(serverName, "SCRIPT")
+	if err != nil {
+		return err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	commandArgs := []interface{}{"FLUSH"}
+	if async {
+		commandArgs = append(commandArgs, "ASYNC")
+	}
+
+	_, err = conn.Do(command, commandArgs...)
+	return err
+}
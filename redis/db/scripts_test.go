@@ -0,0 +1,56 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+func TestRunningScriptMissingInCache(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+
+	script := "return redis.call('GET', KEYS[1])"
+	sha := ScriptSHA(script)
+	if sha != "d3c21d0c2b9ca22f82737626a27bcaf5d288f99f" {
+		t.Errorf("got invalid script SHA %v", sha)
+	}
+
+	conn.Command("EVALSHA", sha, 1, "key", "arg").ExpectError(redis.Error("NOSCRIPT No matching script. Please use EVAL."))
+	conn.Command("EVAL", script, 1, "key", "arg").Expect([]byte("value"))
+
+	reply, err := RunScript("server1", 0, script, []string{"key"}, []string{"arg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CommandReply{Type: ReplyString, Value: "value"}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Errorf("got reply %+v, expected %+v", reply, expected)
+	}
+}
+
+func TestRefusingScriptsOnProtectedServer(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	servers := config.Get().Servers
+	srv := servers["server1"]
+	srv.Mode = rd.ModeProtected
+	servers["server1"] = srv
+	defer config.StubConfigLoader{}.Load()
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+
+	_, err := EvalScript("server1", 0, "return redis.call('FLUSHALL')", nil, nil)
+	if _, ok := err.(CommandNotAllowedError); !ok {
+		t.Errorf("got error %v, expected EVAL to be forbidden on a protected server", err)
+	}
+	_, err = LoadScript("server1", "return redis.call('FLUSHALL')")
+	if _, ok := err.(CommandNotAllowedError); !ok {
+		t.Errorf("got error %v, expected SCRIPT LOAD to be forbidden on a protected server", err)
+	}
+}
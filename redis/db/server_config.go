@@ -0,0 +1,228 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+//ConfigParam is a Redis server configuration parameter
+type ConfigParam struct {
+	Name  string
+	Value string
+}
+
+//ConfigParamChange is a result of changing a configuration parameter
+type ConfigParamChange struct {
+	Name      string
+	OldValue  string
+	NewValue  string
+	Rewritten bool
+}
+
+//ConfigValidationError is returned when a new configuration parameter value has a wrong type
+type ConfigValidationError struct {
+	msg string
+}
+
+func (err ConfigValidationError) Error() string {
+	return err.msg
+}
+
+//HiddenConfigValue is returned instead of values of secret parameters
+const HiddenConfigValue = "<hidden>"
+
+var secretConfigParams = map[string]bool{"requirepass": true, "masterauth": true}
+
+var enumConfigParams = map[string][]string{
+	"maxmemory-policy": []string{"volatile-lru", "allkeys-lru", "volatile-lfu", "allkeys-lfu", "volatile-random", "allkeys-random", "volatile-ttl", "noeviction"},
+	"appendfsync":      []string{"always", "everysec", "no"},
+	"loglevel":         []string{"debug", "verbose", "notice", "warning", "nothing"},
+}
+
+var memoryConfigParams = map[string]bool{
+	"maxmemory":                 true,
+	"repl-backlog-size":         true,
+	"client-query-buffer-limit": true,
+	"proto-max-bulk-len":        true,
+	"auto-aof-rewrite-min-size": true,
+}
+
+//GetServerConfig returns server configuration parameters matching a pattern sorted by name
+//values of secret parameters like passwords are replaced with HiddenConfigValue
+func GetServerConfig(serverName, pattern string) ([]ConfigParam, error) {
+	configCommand, err := serverCommand(serverName, "CONFIG")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	r, err := conn.Do(configCommand, "GET", pattern)
+	values, err := redis.Strings(r, err)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]ConfigParam, 0, len(values)/2)
+	for i := 1; i < len(values); i = i + 2 {
+		param := ConfigParam{Name: values[i-1], Value: values[i]}
+		if IsSecretConfigParam(param.Name) {
+			param.Value = HiddenConfigValue
+		}
+		params = append(params, param)
+	}
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+
+	return params, nil
+}
+
+//IsSecretConfigParam returns true if a parameter value is a password which shouldn't be shown
+func IsSecretConfigParam(name string) bool {
+	return secretConfigParams[strings.ToLower(name)]
+}
+
+//SetServerConfigParam validates and changes a configuration parameter
+//the configuration file is rewritten with CONFIG REWRITE if rewrite is true
+func SetServerConfigParam(serverName, name, value string, rewrite bool) (ConfigParamChange, error) {
+	name = strings.ToLower(name)
+	change := ConfigParamChange{Name: name}
+
+	oldValue, err := getSingleConfigParam(serverName, name)
+	if err != nil {
+		return change, err
+	}
+	change.OldValue = oldValue
+
+	err = validateConfigValue(name, oldValue, value)
+	if err != nil {
+		return change, err
+	}
+
+	configCommand, err := serverCommand(serverName, "CONFIG")
+	if err != nil {
+		return change, err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return change, err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do(configCommand, "SET", name, value)
+	if err != nil {
+		return change, err
+	}
+
+	change.NewValue, err = getSingleConfigParam(serverName, name)
+	if err != nil {
+		return change, err
+	}
+
+	if rewrite {
+		err = RewriteServerConfig(serverName)
+		if err != nil {
+			return change, err
+		}
+		change.Rewritten = true
+	}
+
+	return change, nil
+}
+
+//RewriteServerConfig rewrites server configuration file with CONFIG REWRITE
+func RewriteServerConfig(serverName string) error {
+	configCommand, err := serverCommand(serverName, "CONFIG")
+	if err != nil {
+		return err
+	}
+
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do(configCommand, "REWRITE")
+	return err
+}
+
+func getSingleConfigParam(serverName, name string) (string, error) {
+	params, err := GetServerConfig(serverName, name)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range params {
+		if p.Name == name {
+			return p.Value, nil
+		}
+	}
+
+	return "", ConfigValidationError{msg: fmt.Sprintf("unknown config param %v", name)}
+}
+
+//validateConfigValue checks a new value has the same type the parameter has
+//the type is guessed by known parameters lists and the current value
+func validateConfigValue(name, oldValue, newValue string) error {
+	if allowed, prs := enumConfigParams[name]; prs {
+		for _, v := range allowed {
+			if strings.EqualFold(v, newValue) {
+				return nil
+			}
+		}
+		return ConfigValidationError{msg: fmt.Sprintf("%v should be one of: %v", name, strings.Join(allowed, ", "))}
+	}
+
+	if memoryConfigParams[name] {
+		if _, err := parseMemoryValue(newValue); err != nil {
+			return ConfigValidationError{msg: fmt.Sprintf("%v should be a memory size like 100mb", name)}
+		}
+		return nil
+	}
+
+	if oldValue == "yes" || oldValue == "no" {
+		if newValue != "yes" && newValue != "no" {
+			return ConfigValidationError{msg: fmt.Sprintf("%v should be either yes or no", name)}
+		}
+		return nil
+	}
+
+	if _, err := strconv.ParseInt(oldValue, 10, 64); err == nil {
+		if _, err := strconv.ParseInt(newValue, 10, 64); err != nil {
+			return ConfigValidationError{msg: fmt.Sprintf("%v should be an integer", name)}
+		}
+	}
+
+	return nil
+}
+
+//parseMemoryValue parses memory sizes the way Redis config does, e.g. 1024, 100kb, 1gb
+func parseMemoryValue(value string) (int64, error) {
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"kb", 1024}, {"mb", 1024 * 1024}, {"gb", 1024 * 1024 * 1024},
+		{"k", 1000}, {"m", 1000 * 1000}, {"g", 1000 * 1000 * 1000},
+	}
+
+	value = strings.ToLower(value)
+	for _, u := range units {
+		if strings.HasSuffix(value, u.suffix) {
+			n, err := strconv.ParseInt(strings.TrimSuffix(value, u.suffix), 10, 64)
+			return n * u.multiplier, err
+		}
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+)
+
+func TestValidatingConfigValues(t *testing.T) {
+	valid := [][]string{
+		{"maxmemory-policy", "allkeys-lru", "volatile-ttl"},
+		{"maxmemory", "0", "100mb"},
+		{"appendonly", "no", "yes"},
+		{"timeout", "0", "300"},
+		{"dir", "/data", "/var/lib/redis"},
+	}
+	for _, v := range valid {
+		if err := validateConfigValue(v[0], v[1], v[2]); err != nil {
+			t.Errorf("expected %v=%v to be valid, got %v", v[0], v[2], err)
+		}
+	}
+
+	invalid := [][]string{
+		{"maxmemory-policy", "allkeys-lru", "lru"},
+		{"maxmemory", "0", "a lot"},
+		{"appendonly", "no", "true"},
+		{"timeout", "0", "5m"},
+	}
+	for _, v := range invalid {
+		if err := validateConfigValue(v[0], v[1], v[2]); err == nil {
+			t.Errorf("expected %v=%v to be invalid", v[0], v[2])
+		}
+	}
+}
+
+func TestSettingConfigParamWithRenamedCommand(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	servers := config.Get().Servers
+	srv := servers["server1"]
+	srv.RenamedCommands = map[string]string{"config": "RADISH_CONFIG"}
+	servers["server1"] = srv
+	defer config.StubConfigLoader{}.Load()
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("RADISH_CONFIG", "GET", "timeout").
+		ExpectSlice("timeout", "0").
+		ExpectSlice("timeout", "300")
+	conn.Command("RADISH_CONFIG", "SET", "timeout", "300").Expect("OK")
+
+	change, err := SetServerConfigParam("server1", "timeout", "300", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ConfigParamChange{Name: "timeout", OldValue: "0", NewValue: "300"}
+	if !reflect.DeepEqual(change, expected) {
+		t.Errorf("got change %+v, expected %+v", change, expected)
+	}
+
+	srv.RenamedCommands = map[string]string{"CONFIG": ""}
+	servers["server1"] = srv
+	_, err = GetServerConfig("server1", "*")
+	if _, ok := err.(CommandDisabledError); !ok {
+		t.Errorf("expected CommandDisabledError, got %v", err)
+	}
+}
+
+func TestHidingSecretConfigParams(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("CONFIG", "GET", "*").ExpectSlice("requirepass", "s3cret", "masterauth", "s3cret", "timeout", "0")
+
+	params, err := GetServerConfig("server1", "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ConfigParam{{Name: "masterauth", Value: HiddenConfigValue}, {Name: "requirepass", Value: HiddenConfigValue}, {Name: "timeout", Value: "0"}}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("got params %+v, expected %+v", params, expected)
+	}
+}
@@ -76,6 +76,7 @@ func (vInfo *SetValues) calculatePagesCount() (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	defer conn.Close()
 
 	count := 0
 	if vInfo.query.Mask == "*" {
@@ -103,6 +104,7 @@ func (vInfo *SetValues) loadSetValues() error {
 		logger.Error(err)
 		return err
 	}
+	defer conn.Close()
 
 	var (
 		values []string
@@ -146,47 +148,25 @@ func (vInfo *SetValues) loadSetValues() error {
 }
 
 //AddValueToSet adds a new member to a set
-func AddValueToSet(serverName string, dbNum uint8, key, value string) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		return err
-	}
-
-	_, err = conn.Do("SADD", key, value)
-	if err != nil {
-		logger.Error(err)
-		return err
-	}
-
-	return nil
+func AddValueToSet(serverName string, dbNum uint8, key, value, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "add set value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{newCommand("SADD", key, value)}, nil
+	})
 }
 
 //UpdateSetValue updates a set member
-func UpdateSetValue(serverName string, dbNum uint8, key, value, newValue string) error {
-	err := DeleteSetValue(serverName, dbNum, key, value)
-	if err != nil {
-		return err
-	}
-
-	err = AddValueToSet(serverName, dbNum, key, newValue)
-	if err != nil {
-		return err
-	}
-	return nil
+func UpdateSetValue(serverName string, dbNum uint8, key, value, newValue, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "update set value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{
+			newCommand("SREM", key, value),
+			newCommand("SADD", key, newValue),
+		}, nil
+	})
 }
 
 //DeleteSetValue removes a set member
-func DeleteSetValue(serverName string, dbNum uint8, key, value string) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		return err
-	}
-
-	_, err = conn.Do("SREM", key, value)
-	if err != nil {
-		logger.Error(err)
-		return err
-	}
-
-	return nil
+func DeleteSetValue(serverName string, dbNum uint8, key, value, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "delete set value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{newCommand("SREM", key, value)}, nil
+	})
 }
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/sad0vnikov/radish/logger"
+)
+
+//SlowlogEntry is a parsed SLOWLOG GET entry
+type SlowlogEntry struct {
+	ID         int64
+	Timestamp  int64
+	Duration   int64
+	Args       []string
+	ClientAddr string
+	ClientName string
+}
+
+//Command returns an upper-cased command name of slowlog entry
+func (entry SlowlogEntry) Command() string {
+	if len(entry.Args) == 0 {
+		return ""
+	}
+	return strings.ToUpper(entry.Args[0])
+}
+
+//SlowlogGroup is a summary of slowlog entries having the same command name or key prefix
+type SlowlogGroup struct {
+	Name          string
+	Count         int
+	TotalDuration int64
+	MaxDuration   int64
+}
+
+const (
+	//SlowlogGroupByCommand groups slowlog entries by command name
+	SlowlogGroupByCommand = "command"
+	//SlowlogGroupByPrefix groups slowlog entries by key prefix
+	SlowlogGroupByPrefix = "prefix"
+)
+
+//GetSlowlog returns the latest count slowlog entries
+func GetSlowlog(serverName string, count int) ([]SlowlogEntry, error) {
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	r, err := conn.Do("SLOWLOG", "GET", count)
+	rows, err := redis.Values(r, err)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return parseSlowlogEntries(rows)
+}
+
+//GetSlowlogLen returns the current slowlog length
+func GetSlowlogLen(serverName string) (int64, error) {
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	r, err := conn.Do("SLOWLOG", "LEN")
+	return redis.Int64(r, err)
+}
+
+//ResetSlowlog clears the server slowlog
+func ResetSlowlog(serverName string) error {
+	conn, err := connector.GetByName(serverName, 0)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("SLOWLOG", "RESET")
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+//GroupSlowlogEntries groups slowlog entries by command name or by key prefix
+//Groups are sorted by total duration, the slowest go first
+func GroupSlowlogEntries(entries []SlowlogEntry, groupBy, delimiter string) ([]SlowlogGroup, error) {
+	groups := make(map[string]*SlowlogGroup)
+	for _, entry := range entries {
+		var name string
+		switch groupBy {
+		case SlowlogGroupByCommand:
+			name = entry.Command()
+		case SlowlogGroupByPrefix:
+			if len(entry.Args) < 2 {
+				continue
+			}
+			name = strings.Split(entry.Args[1], delimiter)[0]
+		default:
+			return nil, errors.New("unknown slowlog grouping " + groupBy)
+		}
+
+		group, prs := groups[name]
+		if !prs {
+			group = &SlowlogGroup{Name: name}
+			groups[name] = group
+		}
+		group.Count++
+		group.TotalDuration += entry.Duration
+		if entry.Duration > group.MaxDuration {
+			group.MaxDuration = entry.Duration
+		}
+	}
+
+	result := make([]SlowlogGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalDuration == result[j].TotalDuration {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].TotalDuration > result[j].TotalDuration
+	})
+
+	return result, nil
+}
+
+func parseSlowlogEntries(rows []interface{}) ([]SlowlogEntry, error) {
+	entries := make([]SlowlogEntry, 0, len(rows))
+	for _, row := range rows {
+		fields, err := redis.Values(row, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) < 4 {
+			return nil, errors.New("got malformed slowlog entry")
+		}
+
+		entry := SlowlogEntry{}
+		if entry.ID, err = redis.Int64(fields[0], nil); err != nil {
+			return nil, err
+		}
+		if entry.Timestamp, err = redis.Int64(fields[1], nil); err != nil {
+			return nil, err
+		}
+		if entry.Duration, err = redis.Int64(fields[2], nil); err != nil {
+			return nil, err
+		}
+		if entry.Args, err = redis.Strings(fields[3], nil); err != nil {
+			return nil, err
+		}
+		//client address and name are reported since Redis 4.0
+		if len(fields) >= 6 {
+			entry.ClientAddr, _ = redis.String(fields[4], nil)
+			entry.ClientName, _ = redis.String(fields[5], nil)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
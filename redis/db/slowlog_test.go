@@ -0,0 +1,76 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+)
+
+func TestGettingSlowlog(t *testing.T) {
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+
+	conn.Command("SLOWLOG", "GET", 2).Expect([]interface{}{
+		[]interface{}{
+			int64(14), int64(1309448221), int64(15),
+			[]interface{}{[]byte("get"), []byte("user:1")},
+			[]byte("127.0.0.1:58217"), []byte("worker"),
+		},
+		[]interface{}{
+			int64(13), int64(1309448128), int64(30),
+			[]interface{}{[]byte("keys"), []byte("*")},
+		},
+	})
+
+	result, err := GetSlowlog("server1", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []SlowlogEntry{
+		SlowlogEntry{ID: 14, Timestamp: 1309448221, Duration: 15, Args: []string{"get", "user:1"}, ClientAddr: "127.0.0.1:58217", ClientName: "worker"},
+		SlowlogEntry{ID: 13, Timestamp: 1309448128, Duration: 30, Args: []string{"keys", "*"}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got invalid slowlog entries %v, expected %v", result, expected)
+	}
+}
+
+func TestGroupingSlowlogEntries(t *testing.T) {
+	entries := []SlowlogEntry{
+		SlowlogEntry{Duration: 10, Args: []string{"get", "user:1"}},
+		SlowlogEntry{Duration: 30, Args: []string{"HGETALL", "user:2"}},
+		SlowlogEntry{Duration: 50, Args: []string{"get", "session:1"}},
+		SlowlogEntry{Duration: 5, Args: []string{"ping"}},
+	}
+
+	byCommand, err := GroupSlowlogEntries(entries, SlowlogGroupByCommand, ":")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SlowlogGroup{
+		SlowlogGroup{Name: "GET", Count: 2, TotalDuration: 60, MaxDuration: 50},
+		SlowlogGroup{Name: "HGETALL", Count: 1, TotalDuration: 30, MaxDuration: 30},
+		SlowlogGroup{Name: "PING", Count: 1, TotalDuration: 5, MaxDuration: 5},
+	}
+	if !reflect.DeepEqual(byCommand, expected) {
+		t.Errorf("got invalid groups %v, expected %v", byCommand, expected)
+	}
+
+	byPrefix, err := GroupSlowlogEntries(entries, SlowlogGroupByPrefix, ":")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []SlowlogGroup{
+		SlowlogGroup{Name: "session", Count: 1, TotalDuration: 50, MaxDuration: 50},
+		SlowlogGroup{Name: "user", Count: 2, TotalDuration: 40, MaxDuration: 30},
+	}
+	if !reflect.DeepEqual(byPrefix, expected) {
+		t.Errorf("got invalid groups %v, expected %v", byPrefix, expected)
+	}
+
+	if _, err := GroupSlowlogEntries(entries, "client", ":"); err == nil {
+		t.Error("expected an error for unknown grouping")
+	}
+}
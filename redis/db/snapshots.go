@@ -0,0 +1,211 @@
+package db
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/sad0vnikov/radish/config"
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+//Snapshot is a key state captured with DUMP before a change made with Radish
+type Snapshot struct {
+	ID        uint64
+	Server    string
+	DB        uint8
+	Key       string
+	Operation string
+	Time      int64
+	//Existed is false if the key didn't exist before the change, restoring such a snapshot deletes the key
+	Existed bool
+	Type    string `json:",omitempty"`
+	//PTTL is the key time to live in milliseconds at the moment of the change, -1 means the key had no expiration
+	PTTL int64
+	//Size is a size of the serialized value in bytes or the key memory usage if it was bigger than the snapshot size limit
+	Size int
+	//Restorable is false if the value was bigger than the snapshot size limit and wasn't saved
+	Restorable bool
+
+	dump []byte
+}
+
+//SnapshotNotFoundError is returned when there is no snapshot with given ID for a key
+type SnapshotNotFoundError struct {
+	ID uint64
+}
+
+func (err SnapshotNotFoundError) Error() string {
+	return "snapshot " + strconv.FormatUint(err.ID, 10) + " not found"
+}
+
+//SnapshotNotRestorableError is returned for snapshots of values which were too big to be saved
+type SnapshotNotRestorableError struct {
+	ID uint64
+}
+
+func (err SnapshotNotRestorableError) Error() string {
+	return "snapshot " + strconv.FormatUint(err.ID, 10) + " has no saved value, the key was bigger than the snapshot size limit"
+}
+
+//snapshotHistory keeps the latest snapshots of all servers, the oldest ones are dropped when it's full
+type snapshotHistory struct {
+	mu        sync.Mutex
+	snapshots []Snapshot
+	lastID    uint64
+}
+
+func (h *snapshotHistory) add(s Snapshot) Snapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	s.ID = h.lastID
+	h.snapshots = append(h.snapshots, s)
+
+	size := config.Get().Snapshots.HistorySize
+	if len(h.snapshots) > size {
+		h.snapshots = append([]Snapshot{}, h.snapshots[len(h.snapshots)-size:]...)
+	}
+	return s
+}
+
+func (h *snapshotHistory) get(id uint64) (Snapshot, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, s := range h.snapshots {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return Snapshot{}, false
+}
+
+//list returns snapshots matching the filter starting from the newest one
+func (h *snapshotHistory) list(filter func(Snapshot) bool) []Snapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result := []Snapshot{}
+	for i := len(h.snapshots) - 1; i >= 0; i-- {
+		if filter(h.snapshots[i]) {
+			result = append(result, h.snapshots[i])
+		}
+	}
+	return result
+}
+
+var snapshots = &snapshotHistory{}
+
+//takeSnapshot saves a key state before a change, values bigger than the snapshot size limit are not saved
+//keys taking more memory than the limit are not serialized at all
+func takeSnapshot(serverName string, dbNum uint8, key, operation string) error {
+	conn, err := connector.GetByName(serverName, dbNum)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return snapshotKey(conn, serverName, dbNum, key, operation)
+}
+
+//snapshotKey saves a key state like takeSnapshot using a connection of the caller to the key database
+func snapshotKey(conn redis.Conn, serverName string, dbNum uint8, key, operation string) error {
+	k, err := dumpSnapshotKey(conn, serverName, key)
+	if err != nil {
+		return err
+	}
+	s, err := newSnapshot(conn, serverName, dbNum, key, operation, k)
+	if err != nil {
+		return err
+	}
+	snapshots.add(s)
+	return nil
+}
+
+//dumpSnapshotKey serializes a key value unless it takes more memory than the snapshot size limit
+func dumpSnapshotKey(conn redis.Conn, serverName, key string) (dumpedKey, error) {
+	return dumpKey(conn, serverName, key, int64(config.Get().Snapshots.MaxValueSize))
+}
+
+//newSnapshot returns a state of a key serialized with dumpSnapshotKey, the snapshot is kept once it's added to the history
+func newSnapshot(conn redis.Conn, serverName string, dbNum uint8, key, operation string, k dumpedKey) (Snapshot, error) {
+	s := Snapshot{Server: serverName, DB: dbNum, Key: key, Operation: operation, Time: time.Now().Unix(), PTTL: -1}
+
+	maxValueSize := config.Get().Snapshots.MaxValueSize
+	if k.exists {
+		pttlCommand, err := serverCommand(serverName, "PTTL")
+		if err != nil {
+			return s, err
+		}
+		s.PTTL, err = redis.Int64(conn.Do(pttlCommand, key))
+		if err != nil {
+			return s, err
+		}
+		s.Existed = true
+		s.Type = k.keyType
+		s.Size = k.size
+	}
+
+	if !k.exists || k.dump != nil && k.size <= maxValueSize {
+		s.Restorable = true
+		s.dump = k.dump
+	}
+
+	return s, nil
+}
+
+//GetSnapshots returns the latest snapshots of a server starting from the newest one
+//snapshots can be filtered by a database and a key, nil dbNum and an empty key match any database and key
+func GetSnapshots(serverName string, dbNum *uint8, key string) []Snapshot {
+	return snapshots.list(func(s Snapshot) bool {
+		return s.Server == serverName && (dbNum == nil || s.DB == *dbNum) && (len(key) == 0 || s.Key == key)
+	})
+}
+
+//RestoreSnapshot puts a key back to the state saved in a snapshot with RESTORE ... REPLACE
+//the key is deleted if it didn't exist when the snapshot was taken, the current key state is saved as a new snapshot
+func RestoreSnapshot(serverName string, dbNum uint8, key string, id uint64) (Snapshot, error) {
+	s, ok := snapshots.get(id)
+	if !ok || s.Server != serverName || s.DB != dbNum || s.Key != key {
+		return Snapshot{}, SnapshotNotFoundError{ID: id}
+	}
+	if !s.Restorable {
+		return Snapshot{}, SnapshotNotRestorableError{ID: id}
+	}
+
+	err := CheckServerMode(serverName, rd.AccessWrite)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	restoreCommand, err := serverCommand(serverName, "RESTORE")
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	err = takeSnapshot(serverName, dbNum, key, "restore snapshot "+strconv.FormatUint(id, 10))
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	conn, err := connector.GetByName(serverName, dbNum)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer conn.Close()
+
+	if !s.Existed {
+		_, err = conn.Do("DEL", key)
+		return s, err
+	}
+
+	ttl := s.PTTL
+	if ttl < 0 {
+		ttl = 0
+	}
+	_, err = conn.Do(restoreCommand, key, ttl, s.dump, "REPLACE")
+	return s, err
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+)
+
+func TestRestoringDeletedKeySnapshot(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("DUMP", "session:1").Expect([]byte("dumped")).Expect(nil)
+	conn.Command("TYPE", "session:1").Expect("hash")
+	conn.Command("PTTL", "session:1").Expect(int64(60000))
+	conn.Command("DEL", "session:1").Expect(int64(1))
+	restore := conn.Command("RESTORE", "session:1", int64(60000), []byte("dumped"), "REPLACE").Expect("OK")
+
+	err := DeleteKey("server1", 2, "session:1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := GetSnapshots("server1", nil, "session:1")
+	if len(list) != 1 || !list[0].Existed || list[0].Type != "hash" || list[0].PTTL != 60000 || list[0].Operation != "delete key" {
+		t.Fatalf("got snapshots %+v, expected a snapshot of deleted hash", list)
+	}
+
+	if _, err := RestoreSnapshot("server1", 0, "session:1", list[0].ID); err == nil {
+		t.Errorf("expected snapshot of db 2 not to be restored to db 0")
+	}
+
+	_, err = RestoreSnapshot("server1", 2, "session:1", list[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Stats(restore) != 1 {
+		t.Errorf("expected the key to be restored with RESTORE ... REPLACE")
+	}
+
+	list = GetSnapshots("server1", nil, "")
+	if len(list) != 2 || list[0].Existed || list[0].Operation != "restore snapshot 1" {
+		t.Errorf("got snapshots %+v, expected the state before restoring to be saved", list)
+	}
+}
+
+func TestKeepingBoundedSnapshotHistory(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	size := config.Get().Snapshots.HistorySize
+	for i := 0; i < size+10; i++ {
+		snapshots.add(Snapshot{Server: "server1", Key: strconv.Itoa(i)})
+	}
+
+	list := GetSnapshots("server1", nil, "")
+	if len(list) != size || list[0].Key != strconv.Itoa(size+9) || list[size-1].Key != "10" {
+		t.Errorf("got %v snapshots from %v to %v, expected the %v latest ones", len(list), list[0].Key, list[len(list)-1].Key, size)
+	}
+}
+
+func TestNotSerializingKeyBiggerThanSnapshotLimit(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("MEMORY", "USAGE", "events").Expect(int64(config.Get().Snapshots.MaxValueSize + 1))
+	dump := conn.Command("DUMP", "events").Expect([]byte("dumped"))
+	conn.Command("TYPE", "events").Expect("list")
+	conn.Command("PTTL", "events").Expect(int64(-1))
+
+	err := takeSnapshot("server1", 0, "events", "lpush")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := GetSnapshots("server1", nil, "events")
+	if len(list) != 1 || !list[0].Existed || list[0].Restorable || list[0].Type != "list" {
+		t.Errorf("got snapshots %+v, expected a single not restorable snapshot of a list", list)
+	}
+	if conn.Stats(dump) != 0 {
+		t.Errorf("expected a key bigger than the limit not to be serialized")
+	}
+}
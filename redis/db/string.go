@@ -57,20 +57,10 @@ func (StringKey) KeyType() string {
 }
 
 //Set sets string value
-func Set(serverName string, dbNum uint8, key, value string) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		logger.Critical(err)
-		return err
-	}
-
-	_, err = conn.Do("SET", key, value)
-	if err != nil {
-		logger.Error(err)
-		return err
-	}
-
-	return nil
+func Set(serverName string, dbNum uint8, key, value, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "set string value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{newCommand("SET", key, value)}, nil
+	})
 }
 
 //GetStringKeyValue returns a value for STRING type object
@@ -80,6 +70,7 @@ func getStringKeyValue(serverName string, dbNum uint8, key string) (RedisValue,
 	if err != nil {
 		return RedisValue{}, fmt.Errorf("can't connect to server %vInfo", serverName)
 	}
+	defer conn.Close()
 
 	result, err := conn.Do("GET", key)
 	if err != nil {
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/sad0vnikov/radish/config"
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+//Topology is a replication graph of configured servers
+//servers which aren't configured in Radish but are connected to configured ones are included too
+type Topology struct {
+	Nodes []TopologyNode
+	Links []TopologyLink
+}
+
+//TopologyNode is a Redis instance identified by its address
+//Server is a configured server name, it's empty for instances missing in Radish config
+type TopologyNode struct {
+	ID          string
+	Server      string `json:",omitempty"`
+	Reachable   bool
+	Error       string `json:",omitempty"`
+	Replication rd.ReplicationStat
+}
+
+//TopologyLink is a replication link between a master and a replica
+//State is reported by the master and MasterLinkStatus is reported by the replica,
+//OffsetLag is a count of bytes the replica is behind the master
+type TopologyLink struct {
+	Master           string
+	Replica          string
+	State            string `json:",omitempty"`
+	MasterLinkStatus string `json:",omitempty"`
+	LagSeconds       int64
+	Offset           int64
+	OffsetLag        int64
+}
+
+//lookupHost resolves configured server hosts to match them with addresses reported by INFO
+var (
+	defaultLookupHost = net.LookupHost
+	lookupHost        = defaultLookupHost
+)
+
+//GetTopology returns a replication graph of all configured servers
+func GetTopology() Topology {
+	servers := config.Get().Servers
+	infos := make(map[string]rd.InfoReplication)
+	errs := make(map[string]error)
+	for name := range servers {
+		info, err := GetServerInfo(name, "replication")
+		if err != nil {
+			errs[name] = err
+			continue
+		}
+		infos[name] = info.Replication
+	}
+
+	return buildTopology(servers, infos, errs)
+}
+
+func buildTopology(servers map[string]rd.Server, infos map[string]rd.InfoReplication, errs map[string]error) Topology {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	//every address a configured server can be reported with points to the server node ID
+	addresses := make(map[string]string)
+	for _, name := range names {
+		srv := servers[name]
+		port := strconv.Itoa(srv.Port)
+		id := net.JoinHostPort(srv.Host, port)
+		addresses[id] = id
+		ips, _ := lookupHost(srv.Host)
+		for _, ip := range ips {
+			if _, prs := addresses[net.JoinHostPort(ip, port)]; !prs {
+				addresses[net.JoinHostPort(ip, port)] = id
+			}
+		}
+	}
+	nodeID := func(host string, port int64) string {
+		address := net.JoinHostPort(host, strconv.FormatInt(port, 10))
+		if id, prs := addresses[address]; prs {
+			return id
+		}
+		return address
+	}
+
+	nodes := make(map[string]*TopologyNode)
+	for _, name := range names {
+		srv := servers[name]
+		node := &TopologyNode{ID: nodeID(srv.Host, int64(srv.Port)), Server: name}
+		if err, prs := errs[name]; prs {
+			node.Error = err.Error()
+		}
+		if info, prs := infos[name]; prs {
+			node.Reachable = true
+			node.Replication = rd.NewReplicationStat(info)
+		}
+		nodes[node.ID] = node
+	}
+	addNode := func(id string) {
+		if _, prs := nodes[id]; !prs {
+			nodes[id] = &TopologyNode{ID: id}
+		}
+	}
+
+	links := []TopologyLink{}
+	linkIndex := make(map[[2]string]int)
+	for _, name := range names {
+		master := nodes[nodeID(servers[name].Host, int64(servers[name].Port))]
+		if !master.Reachable {
+			continue
+		}
+		for _, replica := range master.Replication.Replicas {
+			replicaID := nodeID(replica.IP, replica.Port)
+			addNode(replicaID)
+			linkIndex[[2]string{master.ID, replicaID}] = len(links)
+			links = append(links, TopologyLink{
+				Master:     master.ID,
+				Replica:    replicaID,
+				State:      replica.State,
+				LagSeconds: replica.Lag,
+				Offset:     replica.Offset,
+				OffsetLag:  master.Replication.Offset - replica.Offset,
+			})
+		}
+	}
+
+	//replicas which lost connection to a master aren't listed by it, so links are completed from the replicas side
+	for _, name := range names {
+		replica := nodes[nodeID(servers[name].Host, int64(servers[name].Port))]
+		if !replica.Reachable || replica.Replication.Role != "slave" || len(replica.Replication.MasterHost) == 0 {
+			continue
+		}
+		masterID := nodeID(replica.Replication.MasterHost, replica.Replication.MasterPort)
+		if i, prs := linkIndex[[2]string{masterID, replica.ID}]; prs {
+			links[i].MasterLinkStatus = replica.Replication.MasterLinkStatus
+			continue
+		}
+
+		addNode(masterID)
+		link := TopologyLink{
+			Master:           masterID,
+			Replica:          replica.ID,
+			MasterLinkStatus: replica.Replication.MasterLinkStatus,
+			LagSeconds:       replica.Replication.MasterLastIOSecondsAgo,
+			Offset:           replica.Replication.Offset,
+		}
+		if master := nodes[masterID]; master.Reachable {
+			link.OffsetLag = master.Replication.Offset - replica.Replication.Offset
+		}
+		links = append(links, link)
+	}
+
+	topology := Topology{Nodes: make([]TopologyNode, 0, len(nodes)), Links: links}
+	for _, node := range nodes {
+		topology.Nodes = append(topology.Nodes, *node)
+	}
+	sort.Slice(topology.Nodes, func(i, j int) bool {
+		return topology.Nodes[i].ID < topology.Nodes[j].ID
+	})
+
+	return topology
+}
+
+//FilterServers returns the topology without configured servers which aren't visible and their links,
+//instances missing in Radish config are kept only if they are linked to a visible server
+func (t Topology) FilterServers(visible func(server string) bool) Topology {
+	hidden := make(map[string]bool)
+	for _, node := range t.Nodes {
+		if len(node.Server) > 0 && !visible(node.Server) {
+			hidden[node.ID] = true
+		}
+	}
+
+	linked := make(map[string]bool)
+	filtered := Topology{Nodes: []TopologyNode{}, Links: []TopologyLink{}}
+	for _, link := range t.Links {
+		if hidden[link.Master] || hidden[link.Replica] {
+			continue
+		}
+		linked[link.Master] = true
+		linked[link.Replica] = true
+		filtered.Links = append(filtered.Links, link)
+	}
+	for _, node := range t.Nodes {
+		if hidden[node.ID] || (len(node.Server) == 0 && !linked[node.ID]) {
+			continue
+		}
+		filtered.Nodes = append(filtered.Nodes, node)
+	}
+
+	return filtered
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+func TestBuildingTopology(t *testing.T) {
+	lookupHost = func(host string) ([]string, error) {
+		ips := map[string][]string{"master": {"10.0.0.1"}, "replica1": {"10.0.0.2"}, "replica2": {"10.0.0.3"}}
+		return ips[host], nil
+	}
+	defer func() {
+		lookupHost = defaultLookupHost
+	}()
+
+	servers := map[string]rd.Server{
+		"master":   rd.NewServer("master", "master", 6379),
+		"replica1": rd.NewServer("replica1", "replica1", 6379),
+		"replica2": rd.NewServer("replica2", "replica2", 6379),
+		"broken":   rd.NewServer("broken", "broken", 6379),
+	}
+	infos := map[string]rd.InfoReplication{
+		"master": {Role: "master", MasterReplOffset: 1000, ConnectedSlaves: 2, Replicas: []rd.ReplicaInfo{
+			{IP: "10.0.0.2", Port: 6379, State: "online", Offset: 900, Lag: 1},
+			{IP: "10.0.0.9", Port: 6379, State: "online", Offset: 1000, Lag: 0},
+		}},
+		"replica1": {Role: "slave", MasterHost: "10.0.0.1", MasterPort: 6379, MasterLinkStatus: "up", SlaveReplOffset: 900},
+		"replica2": {Role: "slave", MasterHost: "10.0.0.1", MasterPort: 6379, MasterLinkStatus: "down", MasterLastIOSecondsAgo: -1, SlaveReplOffset: 400},
+	}
+	errs := map[string]error{"broken": errors.New("connection refused")}
+
+	topology := buildTopology(servers, infos, errs)
+
+	ids := []string{}
+	for _, node := range topology.Nodes {
+		ids = append(ids, node.ID)
+	}
+	expectedIDs := []string{"10.0.0.9:6379", "broken:6379", "master:6379", "replica1:6379", "replica2:6379"}
+	if !reflect.DeepEqual(ids, expectedIDs) {
+		t.Errorf("got nodes %v, expected %v", ids, expectedIDs)
+	}
+	if topology.Nodes[1].Reachable || topology.Nodes[1].Error != "connection refused" {
+		t.Errorf("got invalid unreachable node %+v", topology.Nodes[1])
+	}
+
+	expectedLinks := []TopologyLink{
+		{Master: "master:6379", Replica: "replica1:6379", State: "online", MasterLinkStatus: "up", LagSeconds: 1, Offset: 900, OffsetLag: 100},
+		{Master: "master:6379", Replica: "10.0.0.9:6379", State: "online", Offset: 1000},
+		{Master: "master:6379", Replica: "replica2:6379", MasterLinkStatus: "down", LagSeconds: -1, Offset: 400, OffsetLag: 600},
+	}
+	if !reflect.DeepEqual(topology.Links, expectedLinks) {
+		t.Errorf("got links %+v, expected %+v", topology.Links, expectedLinks)
+	}
+}
+
+func TestFilteringTopologyServers(t *testing.T) {
+	topology := Topology{
+		Nodes: []TopologyNode{{ID: "10.0.0.9:6379"}, {ID: "master:6379", Server: "master"}, {ID: "replica1:6379", Server: "replica1"}},
+		Links: []TopologyLink{{Master: "master:6379", Replica: "replica1:6379"}, {Master: "master:6379", Replica: "10.0.0.9:6379"}},
+	}
+
+	filtered := topology.FilterServers(func(server string) bool {
+		return server != "master"
+	})
+
+	expected := Topology{Nodes: []TopologyNode{{ID: "replica1:6379", Server: "replica1"}}, Links: []TopologyLink{}}
+	if !reflect.DeepEqual(filtered, expected) {
+		t.Errorf("got topology %+v, expected %+v", filtered, expected)
+	}
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+//command is a Redis command with its arguments
+type command struct {
+	name string
+	args []interface{}
+}
+
+func newCommand(name string, args ...interface{}) command {
+	return command{name: name, args: args}
+}
+
+//TransactionAbortedError is returned when EXEC is aborted because a key watched with WATCH was changed
+type TransactionAbortedError struct{}
+
+func (err TransactionAbortedError) Error() string {
+	return "transaction is aborted, a watched key was changed"
+}
+
+//TransactionCommandError is returned when a command of a transaction fails
+//Redis doesn't roll back a transaction, so the other commands of it are executed anyway
+type TransactionCommandError struct {
+	Command string
+	//Index is the command number in the transaction starting from 0
+	Index int
+	Err   error
+}
+
+func (err TransactionCommandError) Error() string {
+	return fmt.Sprintf("command %v (#%v in the transaction) failed: %v", err.Command, err.Index+1, err.Err)
+}
+
+//Unwrap returns the command error
+func (err TransactionCommandError) Unwrap() error {
+	return err.Err
+}
+
+//transaction runs commands within MULTI/EXEC on a single connection to a database and returns their replies
+func transaction(serverName string, dbNum uint8, commands ...command) ([]interface{}, error) {
+	conn, err := connector.GetByName(serverName, dbNum)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return execTransaction(conn, commands)
+}
+
+//execTransaction runs commands within MULTI/EXEC and returns their replies
+//if a command is rejected while being queued, the transaction is discarded and none of the commands is executed,
+//a failed command error is returned as TransactionCommandError
+func execTransaction(conn redis.Conn, commands []command) ([]interface{}, error) {
+	_, err := conn.Do("MULTI")
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range commands {
+		_, err = conn.Do(c.name, c.args...)
+		if err != nil {
+			conn.Do("DISCARD")
+			return nil, TransactionCommandError{Command: c.name, Index: i, Err: err}
+		}
+	}
+
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err == redis.ErrNil {
+		return nil, TransactionAbortedError{}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, reply := range replies {
+		if rerr, ok := reply.(redis.Error); ok {
+			return replies, TransactionCommandError{Command: commands[i].name, Index: i, Err: rerr}
+		}
+	}
+	return replies, nil
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+)
+
+func TestRunningTransaction(t *testing.T) {
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("MULTI").Expect("OK")
+	conn.Command("HSET", "user:1", "name", "alice").Expect("QUEUED")
+	conn.Command("EXPIRE", "user:1", 60).Expect("QUEUED")
+	exec := conn.Command("EXEC").Expect([]interface{}{int64(1), int64(1)})
+
+	replies, err := transaction("server1", 0, newCommand("HSET", "user:1", "name", "alice"), newCommand("EXPIRE", "user:1", 60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replies, []interface{}{int64(1), int64(1)}) {
+		t.Errorf("got replies %v, expected replies of both commands", replies)
+	}
+	if conn.Stats(exec) != 1 {
+		t.Errorf("expected the transaction to be executed")
+	}
+}
+
+func TestDiscardingTransactionWithRejectedCommand(t *testing.T) {
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("MULTI").Expect("OK")
+	conn.Command("SREM", "tags", "old").Expect("QUEUED")
+	conn.Command("SADD", "tags").ExpectError(redis.Error("ERR wrong number of arguments for 'sadd' command"))
+	discard := conn.Command("DISCARD").Expect("OK")
+	exec := conn.Command("EXEC").Expect([]interface{}{})
+
+	_, err := transaction("server1", 0, newCommand("SREM", "tags", "old"), newCommand("SADD", "tags"))
+	if cerr, ok := err.(TransactionCommandError); !ok || cerr.Command != "SADD" || cerr.Index != 1 {
+		t.Errorf("got error %v, expected SADD command error", err)
+	}
+	if conn.Stats(discard) != 1 || conn.Stats(exec) != 0 {
+		t.Errorf("expected the transaction to be discarded")
+	}
+}
+
+func TestReturningFailedTransactionCommandError(t *testing.T) {
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("MULTI").Expect("OK")
+	conn.Command("LSET", "queue", 10, "RADISH_DELETED").Expect("QUEUED")
+	conn.Command("LREM", "queue", 1, "RADISH_DELETED").Expect("QUEUED")
+	conn.Command("EXEC").Expect([]interface{}{redis.Error("ERR index out of range"), int64(0)})
+
+	_, err := transaction("server1", 0, newCommand("LSET", "queue", 10, "RADISH_DELETED"), newCommand("LREM", "queue", 1, "RADISH_DELETED"))
+	cerr, ok := err.(TransactionCommandError)
+	if !ok || cerr.Command != "LSET" || cerr.Index != 0 || cerr.Err != redis.Error("ERR index out of range") {
+		t.Errorf("got error %v, expected LSET command error", err)
+	}
+}
+
+func TestReturningAbortedTransactionError(t *testing.T) {
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("MULTI").Expect("OK")
+	conn.Command("DEL", "user:1").Expect("QUEUED")
+	conn.Command("EXEC").Expect(nil)
+
+	_, err := transaction("server1", 0, newCommand("DEL", "user:1"))
+	if _, ok := err.(TransactionAbortedError); !ok {
+		t.Errorf("got error %v, expected TransactionAbortedError", err)
+	}
+}
+
+func TestRenamingSetMemberInTransaction(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	snapshots = &snapshotHistory{}
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("DUMP", "tags").Expect([]byte("dumped"))
+	conn.Command("TYPE", "tags").Expect("set")
+	conn.Command("PTTL", "tags").Expect(int64(-1))
+	multi := conn.Command("MULTI").Expect("OK")
+	conn.Command("SREM", "tags", "old").Expect("QUEUED")
+	conn.Command("SADD", "tags", "new").Expect("QUEUED")
+	conn.Command("EXEC").Expect([]interface{}{int64(1), redis.Error("WRONGTYPE Operation against a key holding the wrong kind of value")})
+
+	err := UpdateSetValue("server1", 0, "tags", "old", "new", "")
+	if _, ok := err.(TransactionCommandError); !ok {
+		t.Errorf("got error %v, expected SADD command error", err)
+	}
+	if conn.Stats(multi) != 1 {
+		t.Errorf("expected the member to be renamed in a transaction")
+	}
+}
@@ -0,0 +1,423 @@
+package db
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/sad0vnikov/radish/config"
+	rd "github.com/sad0vnikov/radish/redis"
+	"github.com/sad0vnikov/radish/storage"
+)
+
+//TrashedKey is a deleted key kept in the trash
+type TrashedKey struct {
+	ID   string
+	Key  string
+	DB   uint8
+	Type string
+	//PTTL is the key time to live in milliseconds at the moment of deletion, -1 means the key had no expiration
+	PTTL int64
+	//Size is a size of the serialized value in bytes
+	Size    int
+	Deleted int64
+	//Expires is a time the key is removed from the trash at
+	Expires int64
+}
+
+//TrashNotConfiguredError is returned for servers without trash
+type TrashNotConfiguredError struct {
+	Server string
+}
+
+func (err TrashNotConfiguredError) Error() string {
+	return "trash is not configured for server " + err.Server
+}
+
+//TrashedKeyNotFoundError is returned when there is no key with given ID in the trash
+type TrashedKeyNotFoundError struct {
+	ID string
+}
+
+func (err TrashedKeyNotFoundError) Error() string {
+	return "key " + err.ID + " not found in the trash"
+}
+
+//KeyExistsError is returned when a key can't be restored because a key with the same name exists
+type KeyExistsError struct {
+	Key string
+}
+
+func (err KeyExistsError) Error() string {
+	return "key " + err.Key + " already exists"
+}
+
+//trash keeps deleted keys serialized with DUMP
+type trash interface {
+	put(k TrashedKey, dump []byte) error
+	list() ([]TrashedKey, error)
+	get(id string) (TrashedKey, []byte, error)
+	remove(id string) error
+}
+
+func serverTrash(serverName string) (trash, error) {
+	server := config.Get().Servers[serverName]
+	if server.Trash == nil {
+		return nil, TrashNotConfiguredError{Server: serverName}
+	}
+
+	if server.Trash.Mode == rd.TrashLocal {
+		return localTrash{server: serverName}, nil
+	}
+	return redisTrash{server: serverName, db: server.Trash.DB, prefix: server.Trash.Prefix}, nil
+}
+
+//trashedKey reads a key value to be put in the server trash when the key is deleted, false is returned if the key doesn't exist
+func trashedKey(conn redis.Conn, serverName string, dbNum uint8, key string) (TrashedKey, []byte, bool, error) {
+	dump, err := redis.Bytes(conn.Do("DUMP", key))
+	if err == redis.ErrNil {
+		return TrashedKey{}, nil, false, nil
+	}
+	if err != nil {
+		return TrashedKey{}, nil, false, err
+	}
+	keyType, err := redis.String(conn.Do("TYPE", key))
+	if err != nil {
+		return TrashedKey{}, nil, false, err
+	}
+	pttl, err := redis.Int64(conn.Do("PTTL", key))
+	if err != nil {
+		return TrashedKey{}, nil, false, err
+	}
+
+	id := make([]byte, 8)
+	_, err = rand.Read(id)
+	if err != nil {
+		return TrashedKey{}, nil, false, err
+	}
+
+	now := time.Now()
+	retention := time.Duration(config.Get().Servers[serverName].Trash.RetentionHours) * time.Hour
+	k := TrashedKey{
+		ID:      hex.EncodeToString(id),
+		Key:     key,
+		DB:      dbNum,
+		Type:    keyType,
+		PTTL:    pttl,
+		Size:    len(dump),
+		Deleted: now.Unix(),
+		Expires: now.Add(retention).Unix(),
+	}
+	return k, dump, true, nil
+}
+
+//GetTrash returns keys kept in the server trash starting from the latest deleted one
+func GetTrash(serverName string) ([]TrashedKey, error) {
+	t, err := serverTrash(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := t.list()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Deleted > keys[j].Deleted
+	})
+	return keys, nil
+}
+
+//GetTrashedKey returns a key kept in the server trash
+func GetTrashedKey(serverName, id string) (TrashedKey, error) {
+	t, err := serverTrash(serverName)
+	if err != nil {
+		return TrashedKey{}, err
+	}
+
+	k, _, err := t.get(id)
+	return k, err
+}
+
+//RestoreTrashedKey puts a key from the trash back to its database and removes it from the trash
+//an existing key with the same name is replaced only if replace is true
+func RestoreTrashedKey(serverName, id string, replace bool) (TrashedKey, error) {
+	err := CheckServerMode(serverName, rd.AccessWrite)
+	if err != nil {
+		return TrashedKey{}, err
+	}
+
+	t, err := serverTrash(serverName)
+	if err != nil {
+		return TrashedKey{}, err
+	}
+
+	k, dump, err := t.get(id)
+	if err != nil {
+		return TrashedKey{}, err
+	}
+
+	restoreCommand, err := serverCommand(serverName, "RESTORE")
+	if err != nil {
+		return TrashedKey{}, err
+	}
+
+	conn, err := connector.GetByName(serverName, k.DB)
+	if err != nil {
+		return TrashedKey{}, err
+	}
+	defer conn.Close()
+
+	ttl := k.PTTL
+	if ttl < 0 {
+		ttl = 0
+	}
+	args := []interface{}{k.Key, ttl, dump}
+	if replace {
+		args = append(args, "REPLACE")
+	}
+	_, err = conn.Do(restoreCommand, args...)
+	if rerr, ok := err.(redis.Error); ok && strings.HasPrefix(string(rerr), "BUSYKEY") {
+		return TrashedKey{}, KeyExistsError{Key: k.Key}
+	}
+	if err != nil {
+		return TrashedKey{}, err
+	}
+
+	return k, t.remove(id)
+}
+
+//PurgeTrash removes a key from the server trash for good, all keys are removed if id is empty
+func PurgeTrash(serverName, id string) error {
+	err := CheckServerMode(serverName, rd.AccessWrite)
+	if err != nil {
+		return err
+	}
+
+	t, err := serverTrash(serverName)
+	if err != nil {
+		return err
+	}
+
+	if len(id) > 0 {
+		return t.remove(id)
+	}
+
+	keys, err := t.list()
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		err = t.remove(k.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//redisTrash keeps every deleted key in a hash in a reserved database of the same server
+//the hash expires when the retention period is over
+type redisTrash struct {
+	server string
+	db     uint8
+	prefix string
+}
+
+var trashedKeyFields = []interface{}{"key", "db", "type", "pttl", "size", "deleted", "expires"}
+
+func (t redisTrash) put(k TrashedKey, dump []byte) error {
+	_, err := transaction(t.server, t.db, t.putCommands(k, dump)...)
+	return err
+}
+
+//putCommands returns commands saving a key in the trash database
+func (t redisTrash) putCommands(k TrashedKey, dump []byte) []command {
+	name := t.prefix + k.ID
+	return []command{
+		newCommand("HSET", name, "key", k.Key, "db", k.DB, "type", k.Type, "pttl", k.PTTL,
+			"size", k.Size, "deleted", k.Deleted, "expires", k.Expires, "dump", dump),
+		newCommand("EXPIREAT", name, k.Expires),
+	}
+}
+
+func (t redisTrash) list() ([]TrashedKey, error) {
+	conn, err := connector.GetByName(t.server, t.db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	keys := []TrashedKey{}
+	cursor := "0"
+	for {
+		values, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", t.prefix+"*", "COUNT", 1000))
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		_, err = redis.Scan(values, &cursor, &names)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			k, err := t.load(conn, name)
+			if _, ok := err.(TrashedKeyNotFoundError); ok {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, k)
+		}
+
+		if cursor == "0" {
+			return keys, nil
+		}
+	}
+}
+
+func (t redisTrash) load(conn redis.Conn, name string) (TrashedKey, error) {
+	id := name[len(t.prefix):]
+	fields, err := redis.Strings(conn.Do("HMGET", append([]interface{}{name}, trashedKeyFields...)...))
+	if err != nil {
+		return TrashedKey{}, err
+	}
+	if len(fields) != len(trashedKeyFields) || len(fields[0]) == 0 {
+		return TrashedKey{}, TrashedKeyNotFoundError{ID: id}
+	}
+
+	dbNum, _ := strconv.ParseUint(fields[1], 10, 8)
+	pttl, _ := strconv.ParseInt(fields[3], 10, 64)
+	size, _ := strconv.Atoi(fields[4])
+	deleted, _ := strconv.ParseInt(fields[5], 10, 64)
+	expires, _ := strconv.ParseInt(fields[6], 10, 64)
+	return TrashedKey{ID: id, Key: fields[0], DB: uint8(dbNum), Type: fields[2], PTTL: pttl, Size: size, Deleted: deleted, Expires: expires}, nil
+}
+
+func (t redisTrash) get(id string) (TrashedKey, []byte, error) {
+	conn, err := connector.GetByName(t.server, t.db)
+	if err != nil {
+		return TrashedKey{}, nil, err
+	}
+	defer conn.Close()
+
+	k, err := t.load(conn, t.prefix+id)
+	if err != nil {
+		return TrashedKey{}, nil, err
+	}
+	dump, err := redis.Bytes(conn.Do("HGET", t.prefix+id, "dump"))
+	if err == redis.ErrNil {
+		return TrashedKey{}, nil, TrashedKeyNotFoundError{ID: id}
+	}
+	return k, dump, err
+}
+
+func (t redisTrash) remove(id string) error {
+	conn, err := connector.GetByName(t.server, t.db)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	removed, err := redis.Int(conn.Do("DEL", t.prefix+id))
+	if err == nil && removed == 0 {
+		return TrashedKeyNotFoundError{ID: id}
+	}
+	return err
+}
+
+//localTrash keeps deleted keys of a server in a JSON file in Radish data directory
+//keys are removed from the file when the retention period is over on the next trash access
+type localTrash struct {
+	server string
+}
+
+type localTrashedKey struct {
+	TrashedKey
+	Dump []byte
+}
+
+var localTrashMu sync.Mutex
+
+func (t localTrash) file() storage.JSONFile {
+	return storage.JSONFile{Path: filepath.Join(config.Get().DataDir, "trash", t.server+".json")}
+}
+
+//update reads the trash file without expired keys, calls fn and writes the file back if fn changed it
+func (t localTrash) update(fn func(keys map[string]localTrashedKey) (bool, error)) error {
+	localTrashMu.Lock()
+	defer localTrashMu.Unlock()
+
+	keys := make(map[string]localTrashedKey)
+	err := t.file().Read(&keys)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	now := time.Now().Unix()
+	for id, k := range keys {
+		if k.Expires <= now {
+			delete(keys, id)
+			changed = true
+		}
+	}
+
+	fnChanged, err := fn(keys)
+	if err != nil {
+		return err
+	}
+	if changed || fnChanged {
+		return t.file().Write(keys)
+	}
+	return nil
+}
+
+func (t localTrash) put(k TrashedKey, dump []byte) error {
+	return t.update(func(keys map[string]localTrashedKey) (bool, error) {
+		keys[k.ID] = localTrashedKey{TrashedKey: k, Dump: dump}
+		return true, nil
+	})
+}
+
+func (t localTrash) list() ([]TrashedKey, error) {
+	result := []TrashedKey{}
+	err := t.update(func(keys map[string]localTrashedKey) (bool, error) {
+		for _, k := range keys {
+			result = append(result, k.TrashedKey)
+		}
+		return false, nil
+	})
+	return result, err
+}
+
+func (t localTrash) get(id string) (TrashedKey, []byte, error) {
+	var result localTrashedKey
+	err := t.update(func(keys map[string]localTrashedKey) (bool, error) {
+		k, ok := keys[id]
+		if !ok {
+			return false, TrashedKeyNotFoundError{ID: id}
+		}
+		result = k
+		return false, nil
+	})
+	return result.TrashedKey, result.Dump, err
+}
+
+func (t localTrash) remove(id string) error {
+	return t.update(func(keys map[string]localTrashedKey) (bool, error) {
+		if _, ok := keys[id]; !ok {
+			return false, TrashedKeyNotFoundError{ID: id}
+		}
+		delete(keys, id)
+		return true, nil
+	})
+}
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/sad0vnikov/radish/config"
+	rd "github.com/sad0vnikov/radish/redis"
+)
+
+func setServerTrash(serverName string, trash *rd.TrashConfig) {
+	servers := config.Get().Servers
+	srv := servers[serverName]
+	srv.Trash = trash
+	servers[serverName] = srv
+}
+
+func TestMovingKeyToLocalTrash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radish-test-trash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config.StubConfigLoader{DataDir: dir}.Load()
+	setServerTrash("server1", &rd.TrashConfig{Mode: rd.TrashLocal, RetentionHours: 1})
+	defer config.StubConfigLoader{}.Load()
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("DUMP", "user:1").Expect([]byte("dumped"))
+	conn.Command("TYPE", "user:1").Expect("string")
+	conn.Command("PTTL", "user:1").Expect(int64(-1))
+	del := conn.Command("DEL", "user:1").Expect(int64(1))
+	conn.Command("RESTORE", "user:1", int64(0), []byte("dumped")).ExpectError(redis.Error("BUSYKEY Target key name already exists."))
+	restore := conn.Command("RESTORE", "user:1", int64(0), []byte("dumped"), "REPLACE").Expect("OK")
+
+	err = DeleteKey("server1", 3, "user:1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Stats(del) != 1 {
+		t.Errorf("expected the key to be deleted after moving it to the trash")
+	}
+
+	keys, err := GetTrash("server1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Key != "user:1" || keys[0].DB != 3 || keys[0].Type != "string" || keys[0].Size != 6 {
+		t.Fatalf("got trash %+v, expected deleted user:1 key", keys)
+	}
+
+	_, err = RestoreTrashedKey("server1", keys[0].ID, false)
+	if _, ok := err.(KeyExistsError); !ok {
+		t.Errorf("got error %v, expected KeyExistsError", err)
+	}
+
+	_, err = RestoreTrashedKey("server1", keys[0].ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Stats(restore) != 1 {
+		t.Errorf("expected the key to be restored with RESTORE ... REPLACE")
+	}
+
+	keys, _ = GetTrash("server1")
+	if len(keys) != 0 {
+		t.Errorf("expected restored key to be removed from the trash, got %+v", keys)
+	}
+}
+
+func TestListingServerTrash(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	setServerTrash("server1", &rd.TrashConfig{Mode: rd.TrashServer, DB: 15, Prefix: "trash:", RetentionHours: 1})
+	defer config.StubConfigLoader{}.Load()
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("SCAN", "0", "MATCH", "trash:*", "COUNT", 1000).Expect([]interface{}{[]byte("7"), []interface{}{[]byte("trash:a1")}})
+	conn.Command("SCAN", "7", "MATCH", "trash:*", "COUNT", 1000).Expect([]interface{}{[]byte("0"), []interface{}{[]byte("trash:b2")}})
+	conn.Command("HMGET", "trash:a1", "key", "db", "type", "pttl", "size", "deleted", "expires").
+		Expect([]interface{}{[]byte("user:1"), []byte("2"), []byte("hash"), []byte("-1"), []byte("10"), []byte("100"), []byte("3700")})
+	conn.Command("HMGET", "trash:b2", "key", "db", "type", "pttl", "size", "deleted", "expires").
+		Expect([]interface{}{[]byte("user:2"), []byte("0"), []byte("set"), []byte("5000"), []byte("20"), []byte("200"), []byte("3800")})
+
+	keys, err := GetTrash("server1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []TrashedKey{
+		{ID: "b2", Key: "user:2", DB: 0, Type: "set", PTTL: 5000, Size: 20, Deleted: 200, Expires: 3800},
+		{ID: "a1", Key: "user:1", DB: 2, Type: "hash", PTTL: -1, Size: 10, Deleted: 100, Expires: 3700},
+	}
+	if len(keys) != 2 || keys[0] != expected[0] || keys[1] != expected[1] {
+		t.Errorf("got trash %+v, expected %+v", keys, expected)
+	}
+
+	if _, err := GetTrash("server2"); err == nil {
+		t.Errorf("expected error for a server without trash")
+	}
+}
+
+func TestMovingKeyToServerTrashWithinTransaction(t *testing.T) {
+	config.StubConfigLoader{}.Load()
+	setServerTrash("server1", &rd.TrashConfig{Mode: rd.TrashServer, DB: 15, Prefix: "trash:", RetentionHours: 1})
+	defer config.StubConfigLoader{}.Load()
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("DUMP", "user:1").Expect([]byte("dumped"))
+	conn.Command("TYPE", "user:1").Expect("string")
+	conn.Command("PTTL", "user:1").Expect(int64(-1))
+	multi := conn.Command("MULTI").Expect("OK")
+	conn.Command("DEL", "user:1").Expect("QUEUED")
+	trashDB := conn.Command("SELECT", uint8(15)).Expect("QUEUED")
+	put := conn.GenericCommand("HSET").Expect("QUEUED")
+	conn.GenericCommand("EXPIREAT").Expect("QUEUED")
+	keyDB := conn.Command("SELECT", uint8(3)).Expect("QUEUED")
+	conn.Command("EXEC").Expect([]interface{}{int64(1), "OK", int64(8), int64(1), "OK"})
+
+	err := DeleteKey("server1", 3, "user:1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Stats(multi) != 1 || conn.Stats(put) != 1 || conn.Stats(trashDB) != 1 || conn.Stats(keyDB) != 1 {
+		t.Errorf("expected the key to be put to the trash database within the transaction deleting it")
+	}
+}
+
+func TestNotTrashingKeyIfDeletionFails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radish-test-trash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config.StubConfigLoader{DataDir: dir}.Load()
+	setServerTrash("server1", &rd.TrashConfig{Mode: rd.TrashLocal, RetentionHours: 1})
+	defer config.StubConfigLoader{}.Load()
+
+	conn := redigomock.NewConn()
+	connector = &MockedConnections{ConnectionMock: conn}
+	conn.Command("WATCH", "user:1").Expect("OK")
+	conn.Command("DUMP", "user:1").Expect([]byte("dumped"))
+	conn.Command("TYPE", "user:1").Expect("string")
+	conn.Command("PTTL", "user:1").Expect(int64(-1))
+	conn.Command("MULTI").Expect("OK")
+	conn.Command("DEL", "user:1").Expect("QUEUED")
+	conn.Command("EXEC").Expect(nil)
+
+	//the key is changed by someone else after its ETag is checked, so EXEC is aborted
+	err = DeleteKey("server1", 3, "user:1", `"31c4c1c4bd0e7d6376b0648925a30859cd8c281ef7a6d89b79b9a38ddac77e02"`)
+	if _, ok := err.(ETagMismatchError); !ok {
+		t.Fatalf("got error %v, expected ETagMismatchError", err)
+	}
+
+	keys, err := GetTrash("server1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("got trash %+v, expected a key which wasn't deleted not to be trashed", keys)
+	}
+}
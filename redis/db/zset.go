@@ -79,6 +79,7 @@ func (vInfo *ZSetValues) calculatePagesCount() (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	defer conn.Close()
 
 	count := 0
 	if vInfo.query.Mask == "*" {
@@ -104,6 +105,7 @@ func (vInfo *ZSetValues) loadValues() error {
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
 
 	var (
 		values []string
@@ -160,50 +162,25 @@ func (vInfo *ZSetValues) loadValues() error {
 }
 
 //AddZSetValue adds a new sorted set value if it doesn't exist
-func AddZSetValue(serverName string, dbNum uint8, key, value string, score int64) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		return err
-	}
-
-	_, err = conn.Do("ZADD", key, "NX", score, value)
-	if err != nil {
-		logger.Error(err)
-		return err
-	}
-
-	return nil
+func AddZSetValue(serverName string, dbNum uint8, key, value string, score int64, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "add sorted set value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{newCommand("ZADD", key, "NX", score, value)}, nil
+	})
 }
 
 //UpdateZSetValueIfExists updates a ZSet value if it exists
-func UpdateZSetValueIfExists(serverName string, dbNum uint8, key, oldValue, value string, score int64) error {
-	err := DeleteZSetValue(serverName, dbNum, key, oldValue)
-	if err != nil {
-		logger.Error(err)
-		return err
-	}
-
-	err = AddZSetValue(serverName, dbNum, key, value, score)
-	if err != nil {
-		logger.Error(err)
-		return err
-	}
-
-	return nil
+func UpdateZSetValueIfExists(serverName string, dbNum uint8, key, oldValue, value string, score int64, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "update sorted set value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{
+			newCommand("ZREM", key, oldValue),
+			newCommand("ZADD", key, "NX", score, value),
+		}, nil
+	})
 }
 
 //DeleteZSetValue deletes a ZSET member
-func DeleteZSetValue(serverName string, dbNum uint8, key, value string) error {
-	conn, err := connector.GetByName(serverName, dbNum)
-	if err != nil {
-		return err
-	}
-
-	_, err = conn.Do("ZREM", key, value)
-	if err != nil {
-		logger.Error(err)
-		return err
-	}
-
-	return nil
+func DeleteZSetValue(serverName string, dbNum uint8, key, value, ifMatch string) error {
+	return updateKey(serverName, dbNum, key, "delete sorted set value", ifMatch, func(conn redis.Conn) ([]command, error) {
+		return []command{newCommand("ZREM", key, value)}, nil
+	})
 }
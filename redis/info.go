@@ -0,0 +1,172 @@
+package redis
+
+//ServerInfo is a parsed INFO command output
+//Raw stores all the fields of every section including ones not present in typed sections
+type ServerInfo struct {
+	Server       InfoServer
+	Clients      InfoClients
+	Memory       InfoMemory
+	Persistence  InfoPersistence
+	Stats        InfoStats
+	Replication  InfoReplication
+	CPU          InfoCPU
+	CommandStats map[string]CommandStat
+	ErrorStats   map[string]int64
+	Keyspace     map[string]ServerKeyspaceStat
+	Raw          map[string]map[string]string
+}
+
+//InfoServer is an INFO server section
+type InfoServer struct {
+	RedisVersion    string `info:"redis_version"`
+	RedisMode       string `info:"redis_mode"`
+	OS              string `info:"os"`
+	ArchBits        int64  `info:"arch_bits"`
+	MultiplexingAPI string `info:"multiplexing_api"`
+	ProcessID       int64  `info:"process_id"`
+	RunID           string `info:"run_id"`
+	TCPPort         int64  `info:"tcp_port"`
+	UptimeInSeconds int64  `info:"uptime_in_seconds"`
+	UptimeInDays    int64  `info:"uptime_in_days"`
+	Hz              int64  `info:"hz"`
+	Executable      string `info:"executable"`
+	ConfigFile      string `info:"config_file"`
+}
+
+//InfoClients is an INFO clients section
+type InfoClients struct {
+	ConnectedClients            int64 `info:"connected_clients"`
+	MaxClients                  int64 `info:"maxclients"`
+	ClientRecentMaxInputBuffer  int64 `info:"client_recent_max_input_buffer"`
+	ClientRecentMaxOutputBuffer int64 `info:"client_recent_max_output_buffer"`
+	BlockedClients              int64 `info:"blocked_clients"`
+	TrackingClients             int64 `info:"tracking_clients"`
+}
+
+//InfoMemory is an INFO memory section
+type InfoMemory struct {
+	UsedMemory            int64   `info:"used_memory"`
+	UsedMemoryHuman       string  `info:"used_memory_human"`
+	UsedMemoryRSS         int64   `info:"used_memory_rss"`
+	UsedMemoryPeak        int64   `info:"used_memory_peak"`
+	UsedMemoryPeakHuman   string  `info:"used_memory_peak_human"`
+	UsedMemoryDataset     int64   `info:"used_memory_dataset"`
+	UsedMemoryLua         int64   `info:"used_memory_lua"`
+	TotalSystemMemory     int64   `info:"total_system_memory"`
+	MaxMemory             int64   `info:"maxmemory"`
+	MaxMemoryHuman        string  `info:"maxmemory_human"`
+	MaxMemoryPolicy       string  `info:"maxmemory_policy"`
+	MemFragmentationRatio float64 `info:"mem_fragmentation_ratio"`
+	MemAllocator          string  `info:"mem_allocator"`
+}
+
+//InfoPersistence is an INFO persistence section
+type InfoPersistence struct {
+	Loading                  bool   `info:"loading"`
+	RDBChangesSinceLastSave  int64  `info:"rdb_changes_since_last_save"`
+	RDBBgsaveInProgress      bool   `info:"rdb_bgsave_in_progress"`
+	RDBLastSaveTime          int64  `info:"rdb_last_save_time"`
+	RDBLastBgsaveStatus      string `info:"rdb_last_bgsave_status"`
+	RDBLastBgsaveTimeSec     int64  `info:"rdb_last_bgsave_time_sec"`
+	RDBCurrentBgsaveTimeSec  int64  `info:"rdb_current_bgsave_time_sec"`
+	AOFEnabled               bool   `info:"aof_enabled"`
+	AOFRewriteInProgress     bool   `info:"aof_rewrite_in_progress"`
+	AOFRewriteScheduled      bool   `info:"aof_rewrite_scheduled"`
+	AOFLastRewriteTimeSec    int64  `info:"aof_last_rewrite_time_sec"`
+	AOFCurrentRewriteTimeSec int64  `info:"aof_current_rewrite_time_sec"`
+	AOFLastBgrewriteStatus   string `info:"aof_last_bgrewrite_status"`
+	AOFLastWriteStatus       string `info:"aof_last_write_status"`
+}
+
+//InfoStats is an INFO stats section
+type InfoStats struct {
+	TotalConnectionsReceived int64   `info:"total_connections_received"`
+	TotalCommandsProcessed   int64   `info:"total_commands_processed"`
+	InstantaneousOpsPerSec   int64   `info:"instantaneous_ops_per_sec"`
+	TotalNetInputBytes       int64   `info:"total_net_input_bytes"`
+	TotalNetOutputBytes      int64   `info:"total_net_output_bytes"`
+	InstantaneousInputKbps   float64 `info:"instantaneous_input_kbps"`
+	InstantaneousOutputKbps  float64 `info:"instantaneous_output_kbps"`
+	RejectedConnections      int64   `info:"rejected_connections"`
+	ExpiredKeys              int64   `info:"expired_keys"`
+	EvictedKeys              int64   `info:"evicted_keys"`
+	KeyspaceHits             int64   `info:"keyspace_hits"`
+	KeyspaceMisses           int64   `info:"keyspace_misses"`
+	PubsubChannels           int64   `info:"pubsub_channels"`
+	PubsubPatterns           int64   `info:"pubsub_patterns"`
+	LatestForkUsec           int64   `info:"latest_fork_usec"`
+	TotalErrorReplies        int64   `info:"total_error_replies"`
+}
+
+//InfoReplication is an INFO replication section
+type InfoReplication struct {
+	Role                   string `info:"role"`
+	ConnectedSlaves        int64  `info:"connected_slaves"`
+	MasterReplID           string `info:"master_replid"`
+	MasterReplOffset       int64  `info:"master_repl_offset"`
+	MasterHost             string `info:"master_host"`
+	MasterPort             int64  `info:"master_port"`
+	MasterLinkStatus       string `info:"master_link_status"`
+	MasterLastIOSecondsAgo int64  `info:"master_last_io_seconds_ago"`
+	MasterSyncInProgress   bool   `info:"master_sync_in_progress"`
+	SlaveReplOffset        int64  `info:"slave_repl_offset"`
+	SlavePriority          int64  `info:"slave_priority"`
+	SlaveReadOnly          bool   `info:"slave_read_only"`
+	ReplBacklogActive      bool   `info:"repl_backlog_active"`
+	ReplBacklogSize        int64  `info:"repl_backlog_size"`
+	Replicas               []ReplicaInfo
+}
+
+//ReplicaInfo is a replica connected to a master as reported by INFO replication
+type ReplicaInfo struct {
+	IP     string
+	Port   int64
+	State  string
+	Offset int64
+	Lag    int64
+}
+
+//InfoCPU is an INFO cpu section
+type InfoCPU struct {
+	UsedCPUSys          float64 `info:"used_cpu_sys"`
+	UsedCPUUser         float64 `info:"used_cpu_user"`
+	UsedCPUSysChildren  float64 `info:"used_cpu_sys_children"`
+	UsedCPUUserChildren float64 `info:"used_cpu_user_children"`
+}
+
+//CommandStat is a command statistics row of INFO commandstats section
+type CommandStat struct {
+	Calls         int64
+	Usec          int64
+	UsecPerCall   float64
+	RejectedCalls int64
+	FailedCalls   int64
+}
+
+//Section returns a typed INFO section by its name
+func (info ServerInfo) Section(name string) (interface{}, bool) {
+	switch name {
+	case "server":
+		return info.Server, true
+	case "clients":
+		return info.Clients, true
+	case "memory":
+		return info.Memory, true
+	case "persistence":
+		return info.Persistence, true
+	case "stats":
+		return info.Stats, true
+	case "replication":
+		return info.Replication, true
+	case "cpu":
+		return info.CPU, true
+	case "commandstats":
+		return info.CommandStats, true
+	case "errorstats":
+		return info.ErrorStats, true
+	case "keyspace":
+		return info.Keyspace, true
+	}
+
+	return nil, false
+}
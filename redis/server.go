@@ -9,8 +9,42 @@ type Server struct {
 	ConnectionCheckPassed bool
 	KeyspaceStat          map[string]ServerKeyspaceStat
 	ServerStat            ServerStat
+	//RenamedCommands maps commands renamed with rename-command directive to their new names
+	//an empty name means the command is disabled
+	RenamedCommands map[string]string `json:",omitempty"`
+	//AllowedCommands restricts commands which can be run from the console, any command is allowed if it's empty
+	AllowedCommands []string `json:",omitempty"`
+	//DeniedCommands are commands which can't be run from the console
+	DeniedCommands []string `json:",omitempty"`
+	//Mode restricts operations Radish can perform on the server, see ModeReadWrite, ModeReadOnly and ModeProtected
+	Mode string
+	//Trash makes Radish move deleted keys to the trash, keys are deleted immediately if it's not set
+	Trash *TrashConfig `json:",omitempty"`
 }
 
+//TrashConfig configures where deleted keys are kept and for how long
+type TrashConfig struct {
+	//Mode is TrashServer to keep deleted keys on the server itself or TrashLocal to keep them in Radish data directory
+	Mode string
+	//DB is a database deleted keys are kept in with TrashServer mode
+	DB uint8
+	//Prefix is a prefix of keys deleted keys are kept in with TrashServer mode
+	Prefix string
+	//RetentionHours is a period deleted keys are kept for
+	RetentionHours int
+}
+
+const (
+	//TrashServer keeps deleted keys in a reserved database and prefix on the same server
+	TrashServer = "server"
+	//TrashLocal keeps deleted keys in Radish data directory
+	TrashLocal = "local"
+	//DefaultTrashPrefix is a default prefix of keys deleted keys are kept in
+	DefaultTrashPrefix = "radish:trash:"
+	//DefaultTrashRetentionHours is a default period deleted keys are kept for
+	DefaultTrashRetentionHours = 7 * 24
+)
+
 type ServerStat struct {
 	ConnectedClientsCount int64
 	RedisVersion          string
@@ -19,12 +53,81 @@ type ServerStat struct {
 	UsedMemoryBytes       int64
 	MaxMemoryHuman        string
 	MaxMemoryBytes        int64
+	Replication           ReplicationStat
+}
+
+//ReplicationStat is a server replication state
+type ReplicationStat struct {
+	Role string
+	//Offset is the master replication offset for masters and the processed offset for replicas
+	Offset                 int64
+	MasterHost             string `json:",omitempty"`
+	MasterPort             int64  `json:",omitempty"`
+	MasterLinkStatus       string `json:",omitempty"`
+	MasterLastIOSecondsAgo int64  `json:",omitempty"`
+	MasterSyncInProgress   bool   `json:",omitempty"`
+	ConnectedReplicas      int64
+	Replicas               []ReplicaInfo `json:",omitempty"`
+}
+
+//NewReplicationStat returns a replication state built from INFO replication section
+func NewReplicationStat(info InfoReplication) ReplicationStat {
+	stat := ReplicationStat{
+		Role:                   info.Role,
+		Offset:                 info.MasterReplOffset,
+		MasterHost:             info.MasterHost,
+		MasterPort:             info.MasterPort,
+		MasterLinkStatus:       info.MasterLinkStatus,
+		MasterLastIOSecondsAgo: info.MasterLastIOSecondsAgo,
+		MasterSyncInProgress:   info.MasterSyncInProgress,
+		ConnectedReplicas:      info.ConnectedSlaves,
+		Replicas:               info.Replicas,
+	}
+	if info.Role == "slave" && info.SlaveReplOffset > 0 {
+		stat.Offset = info.SlaveReplOffset
+	}
+	return stat
 }
+
 type ServerKeyspaceStat struct {
 	KeysCount int64
+	Expires   int64
+	AvgTTL    int64
 }
 
 //NewServer returns a redis.Server struct with given fields
 func NewServer(name, host string, port int) Server {
-	return Server{Name: name, Host: host, Port: port}
+	return Server{Name: name, Host: host, Port: port, Mode: ModeReadWrite}
+}
+
+const (
+	//ModeReadWrite allows any operations
+	ModeReadWrite = "read-write"
+	//ModeReadOnly allows only viewing data
+	ModeReadOnly = "read-only"
+	//ModeProtected allows editing data, but refuses administrative operations like FLUSHALL or CONFIG SET
+	ModeProtected = "protected"
+)
+
+//AccessLevel is a kind of operations a request performs on a server
+type AccessLevel int
+
+const (
+	//AccessRead is viewing data and server state
+	AccessRead AccessLevel = iota
+	//AccessWrite is changing data
+	AccessWrite
+	//AccessAdmin is changing server state or wiping data
+	AccessAdmin
+)
+
+//Allows returns true if server mode permits operations of a given access level
+func (s Server) Allows(level AccessLevel) bool {
+	switch s.Mode {
+	case ModeReadOnly:
+		return level == AccessRead
+	case ModeProtected:
+		return level != AccessAdmin
+	}
+	return true
 }
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+//JSONFile is a file keeping a JSON-encoded value
+type JSONFile struct {
+	Path string
+}
+
+//Read decodes the file contents into v, v is left untouched if the file doesn't exist yet
+func (f JSONFile) Read(v interface{}) error {
+	contents, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(contents, v)
+}
+
+//Write replaces the file contents with JSON-encoded v
+//the data is written to a temporary file first, so the file is never left partially written
+func (f JSONFile) Write(v interface{}) error {
+	contents, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(f.Path), 0700)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := f.Path + ".tmp"
+	err = ioutil.WriteFile(tmpPath, contents, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, f.Path)
+}
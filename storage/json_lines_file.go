@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+//JSONLinesFile is an append-only file of JSON-encoded values written one per line
+//the file is rotated when it grows bigger than MaxSize, rotated files are named Path.1, Path.2, etc., Path.1 is the newest one
+type JSONLinesFile struct {
+	Path     string
+	MaxSize  int64
+	MaxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+//NewJSONLinesFile returns a file rotated at maxSize bytes keeping maxFiles rotated files
+func NewJSONLinesFile(path string, maxSize int64, maxFiles int) *JSONLinesFile {
+	return &JSONLinesFile{Path: path, MaxSize: maxSize, MaxFiles: maxFiles}
+}
+
+//Append writes JSON-encoded v as a new line
+func (f *JSONLinesFile) Append(v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		err = f.open()
+		if err != nil {
+			return err
+		}
+	}
+	if f.size > 0 && f.size+int64(len(line)) > f.MaxSize {
+		err = f.rotate()
+		if err != nil {
+			return err
+		}
+	}
+
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	return err
+}
+
+func (f *JSONLinesFile) open() error {
+	err := os.MkdirAll(filepath.Dir(f.Path), 0700)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = stat.Size()
+	return nil
+}
+
+func (f *JSONLinesFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return err
+	}
+
+	os.Remove(f.rotatedPath(f.MaxFiles))
+	for i := f.MaxFiles - 1; i >= 1; i-- {
+		err = os.Rename(f.rotatedPath(i), f.rotatedPath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if f.MaxFiles > 0 {
+		err = os.Rename(f.Path, f.rotatedPath(1))
+	} else {
+		err = os.Remove(f.Path)
+	}
+	if err != nil {
+		return err
+	}
+
+	return f.open()
+}
+
+func (f *JSONLinesFile) rotatedPath(n int) string {
+	return f.Path + "." + strconv.Itoa(n)
+}
+
+//ReadReverse calls fn for every line starting from the newest one including lines of rotated files
+//reading stops when fn returns false
+func (f *JSONLinesFile) ReadReverse(fn func(line []byte) (bool, error)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	paths := []string{f.Path}
+	for i := 1; i <= f.MaxFiles; i++ {
+		paths = append(paths, f.rotatedPath(i))
+	}
+
+	for _, path := range paths {
+		contents, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		lines := bytes.Split(bytes.TrimRight(contents, "\n"), []byte("\n"))
+		for i := len(lines) - 1; i >= 0; i-- {
+			if len(lines[i]) == 0 {
+				continue
+			}
+			next, err := fn(lines[i])
+			if err != nil || !next {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+//Close closes the file, it's reopened on the next Append
+func (f *JSONLinesFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
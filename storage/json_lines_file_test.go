@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRotatingJSONLinesFile(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "radish-test-json-lines")
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	//every line is 8 bytes long, so a file keeps two lines
+	f := NewJSONLinesFile(filepath.Join(dir, "log"), 16, 2)
+	defer f.Close()
+	for i := 1000000; i < 1000007; i++ {
+		err := f.Append(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(f.Path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 rotated files to be kept")
+	}
+
+	values := []int{}
+	err := f.ReadReverse(func(line []byte) (bool, error) {
+		var v int
+		err := json.Unmarshal(line, &v)
+		values = append(values, v)
+		return len(values) < 4, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{1000006, 1000005, 1000004, 1000003}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("got values %v, expected %v", values, expected)
+	}
+}
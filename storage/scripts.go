@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sad0vnikov/radish/config"
+)
+
+//Script is a Lua script saved in Radish scripts library
+type Script struct {
+	Name        string
+	Description string
+	Body        string
+	Updated     int64
+}
+
+//ScriptNotFoundError is returned when there is no saved script with given name
+type ScriptNotFoundError struct {
+	Name string
+}
+
+func (err ScriptNotFoundError) Error() string {
+	return "script " + err.Name + " not found"
+}
+
+var scriptsMu sync.Mutex
+
+func scriptsFile() JSONFile {
+	return JSONFile{Path: filepath.Join(config.Get().DataDir, "scripts.json")}
+}
+
+func readScripts() (map[string]Script, error) {
+	scripts := make(map[string]Script)
+	err := scriptsFile().Read(&scripts)
+	return scripts, err
+}
+
+//GetScripts returns all saved scripts sorted by name
+func GetScripts() ([]Script, error) {
+	scriptsMu.Lock()
+	defer scriptsMu.Unlock()
+
+	scripts, err := readScripts()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Script, 0, len(scripts))
+	for _, script := range scripts {
+		result = append(result, script)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+//GetScript returns a saved script by name
+func GetScript(name string) (Script, error) {
+	scriptsMu.Lock()
+	defer scriptsMu.Unlock()
+
+	scripts, err := readScripts()
+	if err != nil {
+		return Script{}, err
+	}
+
+	script, ok := scripts[name]
+	if !ok {
+		return Script{}, ScriptNotFoundError{Name: name}
+	}
+	return script, nil
+}
+
+//SaveScript adds a new script to the library or replaces a script with the same name
+func SaveScript(script Script) (Script, error) {
+	if len(script.Name) == 0 {
+		return Script{}, errors.New("script name can't be empty")
+	}
+
+	scriptsMu.Lock()
+	defer scriptsMu.Unlock()
+
+	scripts, err := readScripts()
+	if err != nil {
+		return Script{}, err
+	}
+
+	script.Updated = time.Now().Unix()
+	scripts[script.Name] = script
+
+	return script, scriptsFile().Write(scripts)
+}
+
+//DeleteScript removes a script from the library
+func DeleteScript(name string) error {
+	scriptsMu.Lock()
+	defer scriptsMu.Unlock()
+
+	scripts, err := readScripts()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := scripts[name]; !ok {
+		return ScriptNotFoundError{Name: name}
+	}
+	delete(scripts, name)
+
+	return scriptsFile().Write(scripts)
+}
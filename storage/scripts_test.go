@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/sad0vnikov/radish/config"
+)
+
+func TestSavingScripts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radish-test-scripts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config.StubConfigLoader{DataDir: dir}.Load()
+
+	_, err = SaveScript(Script{Name: "incr", Body: "return redis.call('INCR', KEYS[1])"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = SaveScript(Script{Name: "get", Body: "return redis.call('GET', KEYS[1])"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scripts, err := GetScripts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) != 2 || scripts[0].Name != "get" || scripts[1].Name != "incr" {
+		t.Errorf("got invalid scripts %+v", scripts)
+	}
+
+	err = DeleteScript("get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetScript("get"); err == nil {
+		t.Error("expected deleted script to be not found")
+	}
+
+	script, err := GetScript("incr")
+	if err != nil || script.Body != "return redis.call('INCR', KEYS[1])" {
+		t.Errorf("got script %+v, error %v", script, err)
+	}
+}